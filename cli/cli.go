@@ -0,0 +1,173 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package cli binds command line arguments into a struct. Positional
+// arguments are referenced by their index with the `arg` tag, flags by their
+// name with the `flag` tag:
+//
+//	type Copy struct {
+//		Source  string `arg:"0"`
+//		Target  string `arg:"1"`
+//		Force   bool   `flag:"force"`
+//		Retries int    `flag:"retries"`
+//	}
+//
+// Flags are accepted as --name=value, --name value and -name value. Flags of
+// bool fields don't consume the following argument, --force alone sets true.
+// All arguments after "--" are positional.
+package cli
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tpauling/handgover/v2"
+)
+
+const (
+	// ArgTag is the struct tag for positional arguments.
+	ArgTag = "arg"
+	// FlagTag is the struct tag for flags.
+	FlagTag = "flag"
+)
+
+// Args holds the parsed command line arguments.
+type Args struct {
+	Positional []string
+	Flags      map[string][]string
+}
+
+// Parse parses args, e.g. os.Args[2:] after a subcommand. Flags whose name is
+// listed in boolFlags never take the following argument as value.
+func Parse(args []string, boolFlags ...string) Args {
+	parsed := Args{Flags: map[string][]string{}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			parsed.Positional = append(parsed.Positional, args[i+1:]...)
+			break
+		}
+		if !isFlag(arg) {
+			parsed.Positional = append(parsed.Positional, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if key, value, ok := strings.Cut(name, "="); ok {
+			parsed.Flags[key] = append(parsed.Flags[key], value)
+			continue
+		}
+
+		value := "true"
+		if !slices.Contains(boolFlags, name) && i+1 < len(args) && !isFlag(args[i+1]) && args[i+1] != "--" {
+			value = args[i+1]
+			i++
+		}
+		parsed.Flags[name] = append(parsed.Flags[name], value)
+	}
+	return parsed
+}
+
+// Sources returns the sources for the `arg` and `flag` tags.
+func (a Args) Sources() []handgover.Source {
+	return []handgover.Source{
+		{
+			Tag: ArgTag,
+			Get: func(field string) (handgover.Valuer, error) {
+				i, err := strconv.Atoi(field)
+				if err != nil {
+					return nil, err
+				}
+				if i < 0 || i >= len(a.Positional) {
+					return nil, nil
+				}
+				return handgover.Value(a.Positional[i]), nil
+			},
+		},
+		{
+			Tag: FlagTag,
+			Get: func(field string) (handgover.Valuer, error) {
+				values, ok := a.Flags[field]
+				if !ok {
+					return nil, nil
+				}
+				return handgover.Value(values...), nil
+			},
+		},
+	}
+}
+
+// Bind parses args and fills obj from them. Fields whose argument or flag is
+// not given are left untouched.
+func Bind(args []string, obj interface{}) error {
+	return handgover.From(Parse(args, boolFlags(obj)...).Sources()).To(obj)
+}
+
+// boolFlags returns the flag names of the bool fields of obj, including the
+// fields of embedded and nested structs.
+func boolFlags(obj interface{}) []string {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return appendBoolFlags(nil, t, "", []reflect.Type{t})
+}
+
+// appendBoolFlags walks the fields of t like handgover walks sections: struct
+// fields with the `prefix` option add their name to the flag names of their
+// fields, struct fields without a flag tag are walked as they are.
+func appendBoolFlags(names []string, t reflect.Type, prefix string, path []reflect.Type) []string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup(FlagTag)
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		section := slices.Contains(strings.Split(options, ","), "prefix")
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case tagged && !section:
+			if ft.Kind() == reflect.Bool {
+				names = append(names, prefix+name)
+			}
+		case ft.Kind() == reflect.Struct && !slices.Contains(path, ft):
+			sectionPrefix := prefix
+			if section {
+				sectionPrefix += name
+			}
+			names = appendBoolFlags(names, ft, sectionPrefix, append(slices.Clip(path), ft))
+		}
+	}
+	return names
+}
+
+// isFlag reports whether arg is a flag. A single dash and negative numbers
+// are positional arguments.
+func isFlag(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpauling/handgover/v2"
+)
+
+type copyCommand struct {
+	Source  string   `arg:"0"`
+	Target  string   `arg:"1"`
+	Force   bool     `flag:"force"`
+	Retries int      `flag:"retries"`
+	Exclude []string `flag:"exclude"`
+	Mode    string   `flag:"mode"`
+}
+
+func TestParse(t *testing.T) {
+
+	args := Parse([]string{"a", "--key=value", "--flag", "b", "-v", "--", "--not-a-flag"}, "v")
+
+	assert.Equal(t, []string{"a", "--not-a-flag"}, args.Positional)
+	assert.Equal(t, map[string][]string{
+		"key":  {"value"},
+		"flag": {"b"},
+		"v":    {"true"},
+	}, args.Flags)
+}
+
+func TestBind(t *testing.T) {
+
+	c := copyCommand{Mode: "default"}
+	err := Bind([]string{
+		"--force", "src",
+		"--retries", "3",
+		"--exclude=*.tmp", "--exclude", "*.log",
+		"dst",
+	}, &c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, copyCommand{
+		Source:  "src",
+		Target:  "dst",
+		Force:   true,
+		Retries: 3,
+		Exclude: []string{"*.tmp", "*.log"},
+		Mode:    "default",
+	}, c)
+}
+
+func TestBindWithNegativeNumber(t *testing.T) {
+
+	var c struct {
+		Offset int `flag:"offset"`
+		Value  int `arg:"0"`
+	}
+
+	assert.NoError(t, Bind([]string{"--offset", "-5", "-1"}, &c))
+	assert.Equal(t, -5, c.Offset)
+	assert.Equal(t, -1, c.Value)
+}
+
+func TestBindWithInvalidValue(t *testing.T) {
+
+	var c copyCommand
+	err := Bind([]string{"--retries=many"}, &c)
+	assert.Error(t, err)
+
+	var parsedErr handgover.Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "retries", parsedErr.Field)
+	assert.Equal(t, FlagTag, parsedErr.Source)
+	assert.Equal(t, "many", parsedErr.Value)
+}
+
+func TestBindWithNestedBoolFlags(t *testing.T) {
+
+	type common struct {
+		Verbose bool `flag:"verbose"`
+	}
+
+	type Common struct {
+		Debug bool `flag:"debug"`
+	}
+
+	var c struct {
+		Common
+		common
+		File   string `arg:"0"`
+		Output struct {
+			Color *bool  `flag:"color"`
+			Path  string `flag:"path"`
+		} `flag:"output.,prefix"`
+		Log struct {
+			Quiet bool `flag:"quiet"`
+		}
+	}
+
+	err := Bind([]string{
+		"--debug", "--verbose", "--output.color", "--quiet", "file.txt",
+		"--output.path", "out",
+	}, &c)
+
+	assert.NoError(t, err)
+	assert.True(t, c.Debug)
+	assert.True(t, c.Verbose)
+	assert.True(t, *c.Output.Color)
+	assert.True(t, c.Log.Quiet)
+	assert.Equal(t, "file.txt", c.File)
+	assert.Equal(t, "out", c.Output.Path)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Concurrency returns a copy of the sources which asks the sources for the
+// fields of a struct concurrently before filling, with at most n gets at a
+// time. The fields are set afterwards, in the same order as without
+// concurrency. Every source is asked for all of its fields, the first error
+// of a source which is not optional cancels the remaining gets. A value of n
+// below 1 disables concurrency.
+func (sources Sources) Concurrency(n int) Sources {
+	sources.concurrency = n
+	return sources
+}
+
+// prefetch returns a copy of the sources in which every source serves the
+// fields of t from values fetched concurrently, if concurrency is enabled.
+func (sources Sources) prefetch(ctx context.Context, t reflect.Type, tags []*fieldTag) (Sources, error) {
+	if sources.concurrency < 1 {
+		return sources, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		source int
+		field  string
+	}
+
+	var (
+		jobs    = make(chan job)
+		results = make([]map[string]Valuer, len(sources.sources))
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		err     error
+	)
+	for i := range results {
+		results[i] = map[string]Valuer{}
+	}
+
+	for w := 0; w < sources.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				source := sources.sources[j.source]
+				v, getErr := source.get(ctx, j.field)
+
+				mu.Lock()
+				switch {
+				case getErr == nil:
+					results[j.source][j.field] = v
+				case err != nil:
+					// filling is aborted already
+				case source.Optional:
+					sources.ignoreError(newError(j.field, source.id(), valuesOf(v), getErr))
+				default:
+					err = newError(j.field, source.id(), valuesOf(v), getErr)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i, source := range sources.sources {
+		seen := map[string]bool{}
+		for _, field := range sources.fields(t, tags, sources.schema, source, nil, []reflect.Type{t}) {
+			if seen[field] {
+				continue
+			}
+			seen[field] = true
+
+			select {
+			case jobs <- job{source: i, field: field}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return sources, err
+	}
+
+	prefetched := make([]Source, len(sources.sources))
+	for i, source := range sources.sources {
+		prefetched[i] = source.serve(results[i])
+	}
+	sources.sources = prefetched
+	return sources, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkConstraints validates a converted field against its options. The min
+// and max options limit the number of elements of slices and the value of
+// numbers, oneof lists the allowed values of strings. The bounds of
+// time.Duration fields are durations, e.g. `min:"1s"`.
+func checkConstraints(property reflect.Value, opts tagOptions) error {
+	for property.Kind() == reflect.Ptr && !property.IsNil() {
+		property = property.Elem()
+	}
+
+	if property.Type() == reflect.TypeOf(time.Duration(0)) {
+		return checkRange(time.Duration(property.Int()), opts, func(s string) (time.Duration, error) {
+			return parseDuration(s, opts)
+		})
+	}
+
+	switch property.Kind() {
+	case reflect.Slice:
+		return checkLength(property.Len(), opts)
+	case reflect.String:
+		return checkOneOf(property.String(), opts)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return checkRange(property.Int(), opts, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return checkRange(property.Uint(), opts, func(s string) (uint64, error) {
+			return strconv.ParseUint(s, 10, 64)
+		})
+	case reflect.Float32, reflect.Float64:
+		return checkRange(property.Float(), opts, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+	default:
+		return nil
+	}
+}
+
+// checkLength validates the number of elements n against the min and max
+// options.
+func checkLength(n int, opts tagOptions) error {
+	if v, ok := opts.lookup("min"); ok {
+		min, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid min option %q", v)
+		}
+		if n < min {
+			return fmt.Errorf("got %d elements, expected at least %d", n, min)
+		}
+	}
+	if v, ok := opts.lookup("max"); ok {
+		max, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid max option %q", v)
+		}
+		if n > max {
+			return fmt.Errorf("got %d elements, expected at most %d", n, max)
+		}
+	}
+	return nil
+}
+
+// checkRange validates the number v against the min and max options, parsed
+// with parse.
+func checkRange[T cmp.Ordered](v T, opts tagOptions, parse func(string) (T, error)) error {
+	if s, ok := opts.lookup("min"); ok {
+		min, err := parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid min option %q", s)
+		}
+		if v < min {
+			return fmt.Errorf("value %v is below the minimum %v", v, min)
+		}
+	}
+	if s, ok := opts.lookup("max"); ok {
+		max, err := parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid max option %q", s)
+		}
+		if v > max {
+			return fmt.Errorf("value %v exceeds the maximum %v", v, max)
+		}
+	}
+	return nil
+}
+
+// checkOneOf validates v against the space separated values of the oneof
+// option, ignoring case if the ignorecase option is set.
+func checkOneOf(v string, opts tagOptions) error {
+	oneOf, ok := opts.lookup("oneof")
+	if !ok {
+		return nil
+	}
+
+	allowed := strings.Fields(oneOf)
+	if opts.has("ignorecase") {
+		if slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, v) }) {
+			return nil
+		}
+	} else if slices.Contains(allowed, v) {
+		return nil
+	}
+	return fmt.Errorf("value %q is not one of %s", v, strings.Join(allowed, ", "))
+}
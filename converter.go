@@ -0,0 +1,72 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeConverter sets property, which is of the registered type, from the
+// values returned by a source.
+type TypeConverter func(property reflect.Value, values []string) error
+
+var (
+	typeConvertersMu sync.RWMutex
+	typeConverters   = map[reflect.Type]TypeConverter{}
+)
+
+// RegisterType registers the conversion of every field of type t, e.g. of a
+// decimal type from a third-party package. A registered type takes precedence
+// over all other conversions but the merge option.
+//
+// Registration should happen at init time.
+func RegisterType(t reflect.Type, converter TypeConverter) {
+	typeConvertersMu.Lock()
+	defer typeConvertersMu.Unlock()
+	typeConverters[t] = converter
+}
+
+func typeConverter(t reflect.Type) (TypeConverter, bool) {
+	typeConvertersMu.RLock()
+	defer typeConvertersMu.RUnlock()
+	converter, ok := typeConverters[t]
+	return converter, ok
+}
+
+// Set converts values into the variable dst points to, the same way fields
+// are filled, e.g. to test a registered TypeConverter. Conversion failures
+// are returned as Error. The variable is left untouched on errors.
+func Set(dst interface{}, values ...string) error {
+	if dst == nil {
+		return errors.New("given destination is nil")
+	}
+	valueOf := reflect.ValueOf(dst)
+	if valueOf.Kind() != reflect.Ptr {
+		return fmt.Errorf("given destination of type %s is not a pointer", valueOf.Type())
+	}
+	if valueOf.IsNil() {
+		return errors.New("given destination is nil")
+	}
+	if len(values) == 0 {
+		return errors.New("no values given")
+	}
+
+	property := valueOf.Elem()
+	converted := reflect.New(property.Type()).Elem()
+	converted.Set(property)
+	if err := setValue(converted, tagOptions{}, values...); err != nil {
+		e := newError("", "", values, err)
+		e.Type = property.Type().String()
+		return e
+	}
+	property.Set(converted)
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package dotenv provides a handgover source which reads its values from
+// .env files.
+//
+// Each line holds a KEY=VALUE pair and may start with "export". Values can be
+// double quoted, which supports the escapes \n, \t, \" and \\, or single
+// quoted, which keeps the value as is. Lines starting with # and trailing
+// comments after unquoted values are ignored.
+package dotenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tpauling/handgover/v2"
+)
+
+// Tag is the struct tag used by Source.
+const Tag = "env"
+
+// Source reads the given files in order and returns a source for the `env`
+// tag serving their values. Keys of later files override the ones of earlier
+// files, keys which are not in any file leave the field unset.
+func Source(paths ...string) (handgover.Source, error) {
+	values := map[string]string{}
+	for _, path := range paths {
+		if err := readFile(path, values); err != nil {
+			return handgover.Source{}, err
+		}
+	}
+
+	return handgover.Source{
+		Tag: Tag,
+		Get: func(key string) (handgover.Valuer, error) {
+			value, ok := values[key]
+			if !ok {
+				return nil, nil
+			}
+			return handgover.Value(value), nil
+		},
+	}, nil
+}
+
+func readFile(path string, values map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parsed, err := Parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	for key, value := range parsed {
+		values[key] = value
+	}
+	return nil
+}
+
+// Parse reads KEY=VALUE pairs from r.
+func Parse(r io.Reader) (map[string]string, error) {
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+func parseValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'', '"':
+		end := closingQuote(value, quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		if quote == '\'' {
+			return value[1:end], nil
+		}
+		return unescape(value[1:end]), nil
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+func closingQuote(value string, quote byte) int {
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && quote == '"':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+
+func unescape(value string) string {
+	return unescaper.Replace(value)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpauling/handgover/v2"
+)
+
+func TestParse(t *testing.T) {
+
+	values, err := Parse(strings.NewReader(`
+# a comment
+PORT=8080
+export HOST = localhost
+NAME="hello \"world\"\n" # comment
+RAW='keep \n as is'
+URL=http://example.com/#anchor
+EMPTY=
+TRAILING=value # comment
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PORT":     "8080",
+		"HOST":     "localhost",
+		"NAME":     "hello \"world\"\n",
+		"RAW":      `keep \n as is`,
+		"URL":      "http://example.com/#anchor",
+		"EMPTY":    "",
+		"TRAILING": "value",
+	}, values)
+}
+
+func TestParseWithInvalidLine(t *testing.T) {
+
+	for _, content := range []string{
+		"NO_VALUE",
+		"=value",
+		"MY KEY=value",
+		`QUOTED="unterminated`,
+		`QUOTED="value" rest`,
+	} {
+		_, err := Parse(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func TestSource(t *testing.T) {
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+
+	assert.NoError(t, os.WriteFile(base, []byte("PORT=8080\nHOST=localhost\n"), 0o600))
+	assert.NoError(t, os.WriteFile(local, []byte("PORT=9090\n"), 0o600))
+
+	source, err := Source(base, local)
+	assert.NoError(t, err)
+
+	var c struct {
+		Port  int    `env:"PORT"`
+		Host  string `env:"HOST"`
+		Debug bool   `env:"DEBUG"`
+	}
+	c.Debug = true
+
+	assert.NoError(t, handgover.From([]handgover.Source{source}).To(&c))
+	assert.Equal(t, 9090, c.Port)
+	assert.Equal(t, "localhost", c.Host)
+	assert.True(t, c.Debug)
+}
+
+func TestSourceWithMissingFile(t *testing.T) {
+
+	_, err := Source(filepath.Join(t.TempDir(), ".env"))
+	assert.Error(t, err)
+}
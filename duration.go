@@ -0,0 +1,114 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDuration parses a duration with time.ParseDuration. With the tag option
+// `duration=extended` the units d (24h) and w (7d) are supported as well.
+// With the tag option `unit`, plain integers are taken in the given unit.
+func parseDuration(value string, opts tagOptions) (time.Duration, error) {
+	if unit, ok := opts.lookup("unit"); ok {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return unitDuration(n, unit)
+		}
+	}
+
+	switch mode, _ := opts.lookup("duration"); mode {
+	case "":
+		return time.ParseDuration(value)
+	case "extended":
+		return parseExtendedDuration(value)
+	default:
+		return 0, fmt.Errorf("unknown duration option %q", mode)
+	}
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// unitDuration returns n times the given unit.
+func unitDuration(n int64, unit string) (time.Duration, error) {
+	scale, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown duration unit %q", unit)
+	}
+	d := time.Duration(n) * scale
+	if d/scale != time.Duration(n) {
+		return 0, fmt.Errorf("duration %d%s overflows time.Duration", n, unit)
+	}
+	return d, nil
+}
+
+var extendedUnits = map[string]float64{
+	"d": 24,
+	"w": 7 * 24,
+}
+
+// parseExtendedDuration expands days and weeks into hours before parsing the
+// duration, e.g. "1d12h" becomes "24h12h".
+func parseExtendedDuration(value string) (time.Duration, error) {
+	var (
+		expanded strings.Builder
+		s        = value
+	)
+
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		expanded.WriteByte(s[0])
+		s = s[1:]
+	}
+
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if i <= 0 {
+			return time.ParseDuration(value)
+		}
+		number := s[:i]
+		s = s[i:]
+
+		j := strings.IndexFunc(s, func(r rune) bool {
+			return (r >= '0' && r <= '9') || r == '.'
+		})
+		if j < 0 {
+			j = len(s)
+		}
+		unit := s[:j]
+		s = s[j:]
+
+		hours, ok := extendedUnits[unit]
+		if !ok {
+			expanded.WriteString(number + unit)
+			continue
+		}
+
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		expanded.WriteString(strconv.FormatFloat(n*hours, 'f', -1, 64) + "h")
+	}
+
+	d, err := time.ParseDuration(expanded.String())
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: units are ns, us, ms, s, m, h, d and w", value)
+	}
+	return d, nil
+}
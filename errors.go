@@ -9,17 +9,40 @@ package handgover
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrUnsupportedKind is wrapped by the errors of fields whose kind can't be
+// filled, e.g. channels, functions, interfaces with methods or non-struct
+// fields with the prefix option.
+var ErrUnsupportedKind = errors.New("unsupported property kind")
+
+// ErrInvalidJSON is wrapped by the errors of struct fields, slices and maps of
+// structs and merged fields whose value can't be decoded as JSON, together
+// with the error of encoding/json.
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// ErrNoSources is returned by To if no sources are given and RequireSources
+// is set.
+var ErrNoSources = errors.New("no sources to fill from")
+
 type Error struct {
 	Field      string
 	Source     string
 	Value      string
 	InnerError error
+	// Options holds the tag options of the field, e.g. the expected layout.
+	// It is nil if the field has no options.
+	Options map[string]string
+	// Path is the dotted path of the struct field, e.g. "Database.Port".
+	Path string
+	// Type is the Go type of the field, e.g. "int8" or "time.Duration".
+	Type string
 }
 
 func newError(field, source string, values []string, err error) Error {
@@ -30,27 +53,76 @@ func newError(field, source string, values []string, err error) Error {
 		InnerError: err,
 	}
 
-	switch ie := e.InnerError.(type) {
-	case *strconv.NumError:
-		e.Value = ie.Num
-	case *time.ParseError:
-		e.Value = ie.Value
-	case *json.UnsupportedValueError:
-		e.Value = ie.Str
+	var (
+		numErr         *strconv.NumError
+		parseErr       *time.ParseError
+		unsupportedErr *json.UnsupportedValueError
+	)
+
+	switch {
+	case errors.As(err, &numErr):
+		e.Value = numErr.Num
+	case errors.As(err, &parseErr):
+		e.Value = parseErr.Value
+	case errors.As(err, &unsupportedErr):
+		e.Value = unsupportedErr.Str
 	default:
-		if len(values) <= 0 {
-			return e
-		}
-		if len(values) == 1 {
-			e.Value = values[0]
-			return e
-		}
-		e.Value = "[" + strings.Join(values, " ") + "]"
+		e.Value = joinValues(values)
 	}
 
 	return e
 }
 
+// joinValues returns a single value as is and several values in brackets.
+func joinValues(values []string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return values[0]
+	default:
+		return "[" + strings.Join(values, " ") + "]"
+	}
+}
+
+// InvalidTargetError is returned if the object to fill is not a pointer to a
+// struct.
+type InvalidTargetError struct {
+	Type reflect.Type
+}
+
+func (e InvalidTargetError) Error() string {
+	return fmt.Sprintf("given object of type %s is not a pointer to a struct", e.Type)
+}
+
 func (te Error) Error() string {
+	if te.Field == "" {
+		// errors of Set, which converts a single value instead of a field
+		return fmt.Sprintf("failed to set value of type %s: %s", te.Type, te.InnerError)
+	}
+	if te.Source == "" {
+		return fmt.Sprintf("failed to set field %q: %s", te.Field, te.InnerError)
+	}
 	return fmt.Sprintf("failed to set field %q from source %q: %s", te.Field, te.Source, te.InnerError)
 }
+
+// Unwrap returns the inner error, so errors.Is and errors.As look into it.
+func (te Error) Unwrap() error {
+	return te.InnerError
+}
+
+// Errors holds the errors of all fields which failed to be set by ToAll.
+// Use errors.As to get the individual Error values.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
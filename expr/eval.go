@@ -0,0 +1,285 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Eval evaluates the expression and returns an int64, float64 or string.
+// Identifiers are resolved through lookup, which has to return one of these
+// types as well.
+func Eval(expression string, lookup func(name string) (interface{}, error)) (interface{}, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens, lookup: lookup}
+	v, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return v, nil
+}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("+-*/()", c):
+			tokens = append(tokens, token{kind: tokenOperator, text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expression) && expression[end] != '"' {
+				if expression[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expression) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expression[i : end+1]})
+			i = end + 1
+		case unicode.IsDigit(c) || c == '.':
+			end := i
+			for end < len(expression) && (unicode.IsDigit(rune(expression[end])) || expression[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expression[i:end]})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(expression) && isIdent(rune(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[i:end]})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdent(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	lookup func(name string) (interface{}, error)
+}
+
+func (p *parser) peekOperator(operators string) (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	t := p.tokens[p.pos]
+	if t.kind != tokenOperator || !strings.Contains(operators, t.text) {
+		return "", false
+	}
+	return t.text, true
+}
+
+// expr = term { ("+" | "-") term }
+func (p *parser) expr() (interface{}, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOperator("+-")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = apply(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// term = unary { ("*" | "/") unary }
+func (p *parser) term() (interface{}, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOperator("*/")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = apply(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// unary = "-" unary | primary
+func (p *parser) unary() (interface{}, error) {
+	if _, ok := p.peekOperator("-"); ok {
+		p.pos++
+		v, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return apply("-", int64(0), v)
+	}
+	return p.primary()
+}
+
+// primary = number | string | identifier | "(" expr ")"
+func (p *parser) primary() (interface{}, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return f, nil
+	case tokenString:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", t.text)
+		}
+		return s, nil
+	case tokenIdent:
+		return p.lookup(t.text)
+	}
+
+	if t.text != "(" {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	v, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.peekOperator(")"); !ok {
+		return nil, errors.New("missing closing parenthesis")
+	}
+	p.pos++
+	return v, nil
+}
+
+func apply(op string, left, right interface{}) (interface{}, error) {
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok || rok {
+		if op != "+" {
+			return nil, fmt.Errorf("operator %q is not supported for strings", op)
+		}
+		if !lok {
+			ls = format(left)
+		}
+		if !rok {
+			rs = format(right)
+		}
+		return ls + rs, nil
+	}
+
+	li, lok := left.(int64)
+	ri, rok := right.(int64)
+	if lok && rok {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		default:
+			if ri == 0 {
+				return nil, errors.New("division by zero")
+			}
+			return li / ri, nil
+		}
+	}
+
+	lf, err := toFloat(left)
+	if err != nil {
+		return nil, err
+	}
+	rf, err := toFloat(right)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	default:
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return lf / rf, nil
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v of type %T", v, v)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package expr provides a handgover source which derives field values from
+// small expressions referencing other fields of the same struct, e.g.
+// `expr:"Port + 1"` or `expr:"Host + \":\" + Port"`.
+//
+// Expressions support integer, float and string literals, field names,
+// parentheses, the unary minus and the operators + - * /. Adding a string to
+// any value concatenates both.
+//
+// Like the tags of other sources, the tag ends at the first comma, which
+// starts the tag options, e.g. `expr:"Port + 1,min=1"`. Expressions can't
+// contain commas, write them as "\x2c" in strings instead.
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tpauling/handgover/v2"
+)
+
+// Tag is the struct tag used by Source.
+const Tag = "expr"
+
+// To fills obj in two phases. First from the given sources, afterwards the
+// fields tagged with `expr` are evaluated against the filled values.
+func To(sources handgover.Sources, obj interface{}) error {
+	if err := sources.To(obj); err != nil {
+		return err
+	}
+	return handgover.From([]handgover.Source{Source(obj)}).To(obj)
+}
+
+// Source returns a source which evaluates the `expr` tag of a field against
+// the current field values of obj. Fields are referenced by their Go name,
+// fields of nested structs by a dotted path.
+func Source(obj interface{}) handgover.Source {
+	return handgover.Source{
+		Tag: Tag,
+		Get: func(expression string) (handgover.Valuer, error) {
+			v, err := Eval(expression, func(name string) (interface{}, error) {
+				return lookup(obj, name)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate %q: %w", expression, err)
+			}
+			return handgover.Value(format(v)), nil
+		},
+	}
+}
+
+func lookup(obj interface{}, name string) (interface{}, error) {
+	v := reflect.ValueOf(obj)
+	for _, part := range strings.Split(name, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, fmt.Errorf("field %q is nil", name)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		field, ok := v.Type().FieldByName(part)
+		if !ok || !field.IsExported() {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	default:
+		return nil, fmt.Errorf("field %q of kind %q is not supported", name, v.Kind())
+	}
+}
+
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
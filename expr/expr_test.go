@@ -0,0 +1,163 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package expr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpauling/handgover/v2"
+)
+
+func TestEval(t *testing.T) {
+
+	fields := map[string]interface{}{
+		"Port": int64(8080),
+		"Host": "localhost",
+		"Rate": 1.5,
+	}
+	lookup := func(name string) (interface{}, error) {
+		v, ok := fields[name]
+		if !ok {
+			return nil, errors.New("unknown field")
+		}
+		return v, nil
+	}
+
+	tests := map[string]interface{}{
+		"Port + 1":                int64(8081),
+		"1 + 2 * 3":               int64(7),
+		"(Port - 80) * 2 / 4":     int64(4000),
+		"10 / 4":                  int64(2),
+		"-Port":                   int64(-8080),
+		"1 - -1":                  int64(2),
+		"Rate * 2":                3.0,
+		"10 / 4.0":                2.5,
+		"Port + Rate":             8081.5,
+		`Host + ":" + Port`:       "localhost:8080",
+		`Host + "/" + (Port + 1)`: "localhost/8081",
+		`1 + 2 + "a"`:             "3a",
+		`"a\tb"`:                  "a\tb",
+	}
+
+	for expression, expected := range tests {
+		v, err := Eval(expression, lookup)
+		assert.NoError(t, err, expression)
+		assert.Equal(t, expected, v, expression)
+	}
+}
+
+func TestEvalWithInvalidExpression(t *testing.T) {
+
+	lookup := func(name string) (interface{}, error) {
+		if name == "Port" {
+			return int64(8080), nil
+		}
+		return nil, errors.New("unknown field")
+	}
+
+	for _, expression := range []string{
+		"",
+		"Port +",
+		"Port + Unknown",
+		"(Port + 1",
+		"Port + 1)",
+		`"unterminated`,
+		`"a" - "b"`,
+		`-"a"`,
+		"Port / 0",
+		"Port / 0.0",
+		"1.2.3",
+		"Port ? 1",
+		"Port Port",
+	} {
+		_, err := Eval(expression, lookup)
+		assert.Error(t, err, expression)
+	}
+}
+
+type database struct {
+	Host string `cfg:"db_host"`
+	Port int    `cfg:"db_port"`
+}
+
+type config struct {
+	Database database
+	Port     int     `cfg:"port"`
+	Admin    uint16  `expr:"Port + 1"`
+	Address  string  `expr:"Database.Host + \":\" + Database.Port"`
+	Ratio    float32 `expr:"Port / 2.0"`
+}
+
+func TestTo(t *testing.T) {
+
+	values := map[string]string{
+		"port":    "8080",
+		"db_host": "db",
+	}
+
+	sources := handgover.From([]handgover.Source{
+		{
+			Tag: "cfg",
+			Get: func(field string) (handgover.Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return handgover.Value(v), nil
+			},
+		},
+	})
+
+	c := config{Database: database{Port: 5432}}
+	assert.NoError(t, To(sources, &c))
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, uint16(8081), c.Admin)
+	assert.Equal(t, "db:5432", c.Address)
+	assert.Equal(t, float32(4040), c.Ratio)
+}
+
+func TestSourceWithInvalidExpression(t *testing.T) {
+
+	var c struct {
+		Port int `expr:"Unknown + 1"`
+	}
+
+	err := handgover.From([]handgover.Source{Source(&c)}).To(&c)
+	assert.Error(t, err)
+
+	var parsedErr handgover.Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "Unknown + 1", parsedErr.Field)
+	assert.Equal(t, Tag, parsedErr.Source)
+	assert.Contains(t, parsedErr.Error(), `failed to evaluate "Unknown + 1"`)
+}
+
+func TestSourceWithComma(t *testing.T) {
+
+	var c struct {
+		Host    string
+		Port    int
+		Address string `expr:"Host + \"\\x2c\" + Port"`
+		Admin   int    `expr:"Port + 1,max=1024"`
+		Split   string `expr:"Host + \",\" + Port"`
+	}
+	c.Host, c.Port = "localhost", 80
+
+	err := handgover.From([]handgover.Source{Source(&c)}).ToAll(&c)
+	assert.Equal(t, "localhost,80", c.Address)
+	assert.Equal(t, 81, c.Admin)
+
+	// the comma ends the expression
+	var parsedErr handgover.Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, `Host + "`, parsedErr.Field)
+	assert.Contains(t, parsedErr.Error(), "unterminated string")
+}
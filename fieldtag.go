@@ -0,0 +1,124 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"reflect"
+	"sync"
+)
+
+// fieldTag is the tag of a struct field. Source tags are parsed once and
+// cached, as the same fields are filled again on every call of To.
+type fieldTag struct {
+	tag reflect.StructTag
+	// name is the name of the field, nested whether it is a nested struct.
+	name   string
+	nested bool
+
+	mu     sync.RWMutex
+	parsed map[string]parsedTag
+}
+
+type parsedTag struct {
+	name string
+	opts tagOptions
+	ok   bool
+}
+
+// parse is like parseTag, but caches the result.
+func (f *fieldTag) parse(key string) (string, tagOptions, bool) {
+	f.mu.RLock()
+	p, cached := f.parsed[key]
+	f.mu.RUnlock()
+	if cached {
+		return p.name, p.opts, p.ok
+	}
+
+	p.name, p.opts, p.ok = parseTag(f.tag, key)
+
+	f.mu.Lock()
+	if f.parsed == nil {
+		f.parsed = map[string]parsedTag{}
+	}
+	f.parsed[key] = p
+	f.mu.Unlock()
+	return p.name, p.opts, p.ok
+}
+
+// parseSource parses the first tag of the source found on the field and
+// returns its key as well. Without any tag of the source, the field name is
+// used if the source has UseFieldNameIfNoTag set. Nested structs are
+// sections and always need a tag.
+func (f *fieldTag) parseSource(source Source) (string, string, tagOptions, bool) {
+	tagged := false
+	for _, key := range source.tagNames() {
+		if name, opts, ok := f.parse(key); ok {
+			return key, name, opts, true
+		}
+		_, found := f.tag.Lookup(key)
+		tagged = tagged || found
+	}
+
+	if !source.UseFieldNameIfNoTag || tagged || f.nested || f.name == "" {
+		return "", "", tagOptions{}, false
+	}
+	name := f.name
+	if source.NameTransform != nil {
+		name = source.NameTransform(name)
+	}
+	return source.id(), name, tagOptions{tag: f.tag}, true
+}
+
+// excludes reports whether the field is excluded from the source by a tag
+// of "-", and no other tag of the source names it. Excluded struct fields are
+// not walked as sections for the source, unlike fields without a tag.
+func (f *fieldTag) excludes(source Source) bool {
+	excluded := false
+	for _, key := range source.tagNames() {
+		if _, _, ok := f.parse(key); ok {
+			return false
+		}
+		value, found := f.tag.Lookup(key)
+		excluded = excluded || found && excludedTag(value)
+	}
+	return excluded
+}
+
+func newFieldTag(field reflect.StructField, tag reflect.StructTag) *fieldTag {
+	return &fieldTag{tag: tag, name: field.Name, nested: isNestedStruct(field.Type)}
+}
+
+// Lookup looks up a struct tag of its own, like reflect.StructTag.
+func (f *fieldTag) Lookup(key string) (string, bool) {
+	return f.tag.Lookup(key)
+}
+
+// fieldTagCache holds the field tags of named struct types. Unnamed types
+// are not cached, as they can be created at runtime without bound, e.g. by
+// reflect.StructOf.
+var fieldTagCache sync.Map
+
+// structTags returns the tags of each field of t.
+func structTags(t reflect.Type) []*fieldTag {
+	if t.Name() != "" {
+		if tags, ok := fieldTagCache.Load(t); ok {
+			return tags.([]*fieldTag)
+		}
+	}
+
+	tags := make([]*fieldTag, t.NumField())
+	for i := range tags {
+		tags[i] = newFieldTag(t.Field(i), t.Field(i).Tag)
+	}
+
+	if t.Name() != "" {
+		cached, _ := fieldTagCache.LoadOrStore(t, tags)
+		return cached.([]*fieldTag)
+	}
+	return tags
+}
@@ -1,4 +1,4 @@
-module github.com/tpauling/handgover
+module github.com/tpauling/handgover/v2
 
 go 1.24
 
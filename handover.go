@@ -8,77 +8,295 @@
 package handgover
 
 import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"math/big"
 	"math/bits"
+	"net"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-func setValue(property reflect.Value, values ...string) error {
+// setValue converts values into property. The merge option is applied first,
+// followed by registered types, time.Time is parsed by its layout, big.Int
+// and big.Float in base 10, url.URL by url.Parse and net.IP and net.IPNet by
+// net.ParseIP and net.ParseCIDR. The Null types of database/sql are set from
+// their value. Then encoding.TextUnmarshaler, encoding.BinaryUnmarshaler,
+// json.Unmarshaler, flag.Value and registered kind parsers take precedence
+// over the kind of the property.
+func setValue(property reflect.Value, opts tagOptions, values ...string) error {
+	if kind := property.Kind(); (kind == reflect.Struct || kind == reflect.Map) && opts.has("merge") {
+		return mergeJSON(property, opts, values[0])
+	}
+
+	if converter, ok := typeConverter(property.Type()); ok {
+		return converter(property, values)
+	}
+
+	if property.Type() == reflect.TypeOf(time.Time{}) {
+		return setTime(property, opts, values)
+	}
+
+	if isSQLNull(property.Type()) {
+		return setSQLNull(property, opts, values)
+	}
+
+	switch property.Type() {
+	case reflect.TypeOf(big.Int{}):
+		return setBigInt(property, values)
+	case reflect.TypeOf(big.Float{}):
+		return setBigFloat(property, values)
+	case reflect.TypeOf(url.URL{}):
+		return setURL(property, values)
+	case reflect.TypeOf(net.IP{}):
+		return setIP(property, values)
+	case reflect.TypeOf(net.IPNet{}):
+		return setIPNet(property, values)
+	}
+
+	if unmarshal, ok := unmarshaler(property); ok {
+		return unmarshal([]byte(values[0]))
+	}
+
+	if parser, ok := kindParser(property.Kind()); ok {
+		return setParsed(property, parser, values)
+	}
+
 	switch kind := property.Kind(); kind {
 	case reflect.Ptr:
-		return setPointer(property, values)
+		return setPointer(property, opts, values)
 	case reflect.Slice:
-		return setSlice(property, values)
+		return setSlice(property, opts, values)
+	case reflect.Map:
+		return setMap(property, opts, values)
 	case reflect.String:
 		return setString(property, values)
 	case reflect.Int:
-		return setInt(property, values, bits.UintSize)
+		return setInt(property, opts, values, bits.UintSize)
 	case reflect.Int8:
-		return setInt(property, values, 8)
+		return setInt(property, opts, values, 8)
 	case reflect.Int16:
-		return setInt(property, values, 16)
+		return setInt(property, opts, values, 16)
 	case reflect.Int32:
-		return setInt(property, values, 32)
+		return setInt(property, opts, values, 32)
 	case reflect.Int64:
-		return setInt(property, values, 64)
+		return setInt(property, opts, values, 64)
 	case reflect.Uint:
-		return setUInt(property, values, bits.UintSize)
+		return setUInt(property, opts, values, bits.UintSize)
 	case reflect.Uint8:
-		return setUInt(property, values, 8)
+		return setUInt(property, opts, values, 8)
 	case reflect.Uint16:
-		return setUInt(property, values, 16)
+		return setUInt(property, opts, values, 16)
 	case reflect.Uint32:
-		return setUInt(property, values, 32)
+		return setUInt(property, opts, values, 32)
 	case reflect.Uint64:
-		return setUInt(property, values, 64)
+		return setUInt(property, opts, values, 64)
+	case reflect.Uintptr:
+		return setUInt(property, opts, values, bits.UintSize)
 	case reflect.Bool:
-		return setBool(property, values)
+		return setBool(property, opts, values)
 	case reflect.Float32:
 		return setFloat(property, values, 32)
 	case reflect.Float64:
 		return setFloat(property, values, 64)
+	case reflect.Complex64:
+		return setComplex(property, values, 64)
+	case reflect.Complex128:
+		return setComplex(property, values, 128)
 	case reflect.Struct:
-		return setStruct(property, values)
+		return setStruct(property, opts, values)
+	case reflect.Interface:
+		return setInterface(property, values)
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+}
+
+// unmarshaler returns the method decoding a value into property, if its
+// pointer implements encoding.TextUnmarshaler or, with lower precedence,
+// encoding.BinaryUnmarshaler, json.Unmarshaler or flag.Value.
+func unmarshaler(property reflect.Value) (func([]byte) error, bool) {
+	if !property.CanAddr() {
+		return nil, false
+	}
+
+	switch u := property.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return u.UnmarshalText, true
+	case encoding.BinaryUnmarshaler:
+		return u.UnmarshalBinary, true
+	case json.Unmarshaler:
+		return u.UnmarshalJSON, true
+	case flag.Value:
+		return func(b []byte) error { return u.Set(string(b)) }, true
 	default:
-		return fmt.Errorf("unsupported property kind %q", kind)
+		return nil, false
 	}
 }
 
-func setPointer(property reflect.Value, values []string) error {
-	property.Set(reflect.New(property.Type().Elem()))
-	return setValue(property.Elem(), values...)
+// setPointer allocates the element of a pointer unless the value is the null
+// sentinel, "null" or the value of the null option, which sets it to nil.
+func setPointer(property reflect.Value, opts tagOptions, values []string) error {
+	null, ok := opts.lookup("null")
+	if !ok {
+		null = "null"
+	}
+	if len(values) == 1 && values[0] == null {
+		property.Set(reflect.Zero(property.Type()))
+		return nil
+	}
+
+	p := reflect.New(property.Type().Elem())
+	if !property.IsNil() {
+		p.Elem().Set(property.Elem())
+	}
+	property.Set(p)
+	return setValue(property.Elem(), opts, values...)
 }
 
-func setStruct(property reflect.Value, values []string) error {
-	switch property.Interface().(type) {
-	case time.Time:
-		t, err := time.Parse(time.RFC3339, values[0])
-		if err != nil {
+// setStruct decodes a struct from JSON, unless another unmarshal function is
+// given by UsingUnmarshal. JSON errors are wrapped with ErrInvalidJSON.
+func setStruct(property reflect.Value, opts tagOptions, values []string) error {
+	s := reflect.New(property.Type())
+	if opts.unmarshal != nil {
+		if err := opts.unmarshal([]byte(values[0]), s.Interface()); err != nil {
 			return err
 		}
-		property.Set(reflect.ValueOf(t))
-	default:
-		s := reflect.New(property.Type())
-		err := json.Unmarshal([]byte(values[0]), s.Interface())
-		if err != nil {
-			return err
+	} else if err := unmarshalJSON([]byte(values[0]), s.Interface()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	property.Set(s.Elem())
+	return nil
+}
+
+// unmarshalNew decodes value into a new value of the type of property with
+// the function given by UsingUnmarshal and sets it.
+func unmarshalNew(property reflect.Value, opts tagOptions, value string) error {
+	v := reflect.New(property.Type())
+	if err := opts.unmarshal([]byte(value), v.Interface()); err != nil {
+		return err
+	}
+	property.Set(v.Elem())
+	return nil
+}
+
+// unmarshalJSON is like json.Unmarshal but decodes numbers into interface
+// values as json.Number, which keeps their exact digits.
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("invalid data after top-level JSON value")
 		}
-		property.Set(s.Elem())
+		return err
+	}
+	return nil
+}
+
+// setTime parses a time with the layout option, RFC3339 if none is given.
+// Numeric values are parsed as Unix timestamps in the scale of the unit
+// option, seconds by default, unless only a layout is given.
+func setTime(property reflect.Value, opts tagOptions, values []string) error {
+	layout, hasLayout := opts.lookup("layout")
+	unit, hasUnit := opts.lookup("unit")
+	if isDigits(values[0]) && (hasUnit || !hasLayout) {
+		return setUnixTime(property, unit, values[0])
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	t, err := time.Parse(layout, values[0])
+	if err != nil {
+		return err
+	}
+	property.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// setBigInt parses a big.Int in base 10, without the prefixes accepted by
+// its encoding.TextUnmarshaler.
+func setBigInt(property reflect.Value, values []string) error {
+	n, ok := new(big.Int).SetString(values[0], 10)
+	if !ok {
+		return fmt.Errorf("invalid big integer %q", values[0])
+	}
+	property.Set(reflect.ValueOf(n).Elem())
+	return nil
+}
+
+func setBigFloat(property reflect.Value, values []string) error {
+	f, _, err := big.ParseFloat(values[0], 10, 0, big.ToNearestEven)
+	if err != nil {
+		return err
+	}
+	property.Set(reflect.ValueOf(f).Elem())
+	return nil
+}
+
+func setURL(property reflect.Value, values []string) error {
+	u, err := url.Parse(values[0])
+	if err != nil {
+		return err
+	}
+	property.Set(reflect.ValueOf(u).Elem())
+	return nil
+}
+
+func setIP(property reflect.Value, values []string) error {
+	ip := net.ParseIP(values[0])
+	if ip == nil {
+		return &net.ParseError{Type: "IP address", Text: values[0]}
+	}
+	property.Set(reflect.ValueOf(ip))
+	return nil
+}
+
+// setIPNet parses a network in CIDR notation, e.g. "192.0.2.0/24".
+func setIPNet(property reflect.Value, values []string) error {
+	_, ipNet, err := net.ParseCIDR(values[0])
+	if err != nil {
+		return err
+	}
+	property.Set(reflect.ValueOf(ipNet).Elem())
+	return nil
+}
+
+// setInterface stores a single value as string and multiple values as
+// []string in an empty interface.
+func setInterface(property reflect.Value, values []string) error {
+	if property.NumMethod() > 0 {
+		return fmt.Errorf("%w: interface type %s, only empty interfaces can be filled", ErrUnsupportedKind, property.Type())
+	}
+
+	if len(values) == 1 {
+		property.Set(reflect.ValueOf(values[0]))
+	} else {
+		property.Set(reflect.ValueOf(values))
 	}
 	return nil
 }
@@ -88,7 +306,23 @@ func setString(property reflect.Value, values []string) error {
 	return nil
 }
 
-func setSlice(property reflect.Value, values []string) error {
+// setSlice replaces the slice with the values, with the append option they
+// are appended to the current elements instead.
+func setSlice(property reflect.Value, opts tagOptions, values []string) error {
+	if !opts.has("append") {
+		return replaceSlice(property, opts, values)
+	}
+
+	n := property.Len()
+	current := property.Slice3(0, n, n)
+	if err := replaceSlice(property, opts, values); err != nil {
+		return err
+	}
+	property.Set(reflect.AppendSlice(current, property))
+	return nil
+}
+
+func replaceSlice(property reflect.Value, opts tagOptions, values []string) error {
 	var (
 		propertyType        = property.Type()
 		propertyElementKind = propertyType.Elem().Kind()
@@ -97,9 +331,22 @@ func setSlice(property reflect.Value, values []string) error {
 	switch propertyElementKind {
 	// case of a byte array
 	case reflect.Uint8:
-		values = strings.Split(values[0], "")
-		for i, c := range values {
-			values[i] = strconv.FormatUint(uint64([]byte(c)[0]), 10)
+		if encoding, ok := opts.lookup("encoding"); ok {
+			return setEncodedBytes(property, encoding, values[0])
+		}
+		setBytes(property, []byte(values[0]))
+		return nil
+	default:
+		if len(values) == 1 && isComposite(propertyType.Elem()) && strings.HasPrefix(strings.TrimSpace(values[0]), "[") {
+			return setJSONSlice(property, opts, values[0])
+		}
+
+		if delim, ok := opts.lookup("delim"); ok && delim != "" && len(values) == 1 {
+			var err error
+			values, err = splitValue(values[0], delim, opts.has("quoted"))
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -109,47 +356,283 @@ func setSlice(property reflect.Value, values []string) error {
 	)
 
 	for i := 0; i < lenVals; i++ {
-		if err := setValue(slice.Index(i), values[i]); err != nil {
-			return err
+		if err := setValue(slice.Index(i), opts, values[i]); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	property.Set(slice)
+	return nil
+}
+
+// setEncodedBytes decodes a byte slice with the encoding option, either
+// "base64" or "hex".
+func setEncodedBytes(property reflect.Value, encoding, value string) error {
+	var (
+		decoded []byte
+		err     error
+	)
+	switch encoding {
+	case "base64":
+		decoded, err = base64.StdEncoding.DecodeString(value)
+	case "hex":
+		decoded, err = hex.DecodeString(value)
+	default:
+		return fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	if err != nil {
+		return err
+	}
+	setBytes(property, decoded)
+	return nil
+}
+
+func setBytes(property reflect.Value, b []byte) {
+	slice := reflect.MakeSlice(property.Type(), len(b), len(b))
+	for i, c := range b {
+		slice.Index(i).SetUint(uint64(c))
+	}
+	property.Set(slice)
+}
+
+// isComposite reports whether values of t are decoded from JSON.
+func isComposite(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	case reflect.Struct:
+		return t != reflect.TypeOf(time.Time{})
+	default:
+		return false
+	}
+}
+
+// setJSONSlice decodes a JSON array into a slice of composite elements. The
+// function given by UsingUnmarshal decodes the whole value instead.
+func setJSONSlice(property reflect.Value, opts tagOptions, value string) error {
+	if opts.unmarshal != nil {
+		return unmarshalNew(property, opts, value)
+	}
+
+	var elements []json.RawMessage
+	if err := unmarshalJSON([]byte(value), &elements); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+
+	slice := reflect.MakeSlice(property.Type(), len(elements), len(elements))
+	for i, element := range elements {
+		if err := unmarshalJSON(element, slice.Index(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("element %d: %w: %w", i, ErrInvalidJSON, err)
 		}
 	}
 	property.Set(slice)
 	return nil
 }
 
-func setInt(property reflect.Value, values []string, size int) error {
+// splitValue splits value at delim. If quoted is set, elements can be quoted
+// like in CSV to contain the delimiter.
+func splitValue(value, delim string, quoted bool) ([]string, error) {
+	if !quoted {
+		return strings.Split(value, delim), nil
+	}
+
+	comma, size := utf8.DecodeRuneInString(delim)
+	if size != len(delim) {
+		return nil, fmt.Errorf("quoted delimiter %q must be a single character", delim)
+	}
+
+	r := csv.NewReader(strings.NewReader(value))
+	r.Comma = comma
+	record, err := r.Read()
+	if err == io.EOF {
+		return []string{""}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Read(); err != io.EOF {
+		return nil, fmt.Errorf("quoted value %q contains more than one record", value)
+	}
+	return record, nil
+}
+
+// setMap fills a map from a single JSON object or from values of the form
+// key=value. Keys and elements are converted like single values.
+func setMap(property reflect.Value, opts tagOptions, values []string) error {
+	if len(values) == 1 && strings.HasPrefix(strings.TrimSpace(values[0]), "{") {
+		return setJSONMap(property, opts, values[0])
+	}
+
+	if delim, ok := opts.lookup("delim"); ok && delim != "" && len(values) == 1 {
+		var err error
+		values, err = splitValue(values[0], delim, opts.has("quoted"))
+		if err != nil {
+			return err
+		}
+	}
+
+	m := reflect.MakeMapWithSize(property.Type(), len(values))
+	for _, value := range values {
+		key, element, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("map entry %q is not of the form key=value", value)
+		}
+		err := setMapIndex(m, opts, key, func(e reflect.Value) error {
+			return setValue(e, opts, element)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	property.Set(m)
+	return nil
+}
+
+// setJSONMap decodes a JSON object into a map. Composite elements are decoded
+// from JSON, all other elements are converted like single values. Maps of
+// composite elements are decoded by the function given by UsingUnmarshal
+// instead, if any.
+func setJSONMap(property reflect.Value, opts tagOptions, value string) error {
+	if opts.unmarshal != nil && isComposite(property.Type().Elem()) {
+		return unmarshalNew(property, opts, value)
+	}
+
+	var object map[string]json.RawMessage
+	if err := unmarshalJSON([]byte(value), &object); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+
+	m := reflect.MakeMapWithSize(property.Type(), len(object))
+	composite := isComposite(property.Type().Elem())
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		raw := object[key]
+		err := setMapIndex(m, opts, key, func(e reflect.Value) error {
+			if composite {
+				if err := unmarshalJSON(raw, e.Addr().Interface()); err != nil {
+					return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+				}
+				return nil
+			}
+
+			element := string(raw)
+			if strings.HasPrefix(element, `"`) {
+				if err := unmarshalJSON(raw, &element); err != nil {
+					return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+				}
+			}
+			return setValue(e, opts, element)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	property.Set(m)
+	return nil
+}
+
+// setMapIndex converts key and sets the element created by setElement in m.
+func setMapIndex(m reflect.Value, opts tagOptions, key string, setElement func(reflect.Value) error) error {
+	k := reflect.New(m.Type().Key()).Elem()
+	if err := setValue(k, opts, key); err != nil {
+		return fmt.Errorf("key %q: %w", key, err)
+	}
+
+	e := reflect.New(m.Type().Elem()).Elem()
+	if err := setElement(e); err != nil {
+		return fmt.Errorf("key %q: %w", key, err)
+	}
+	m.SetMapIndex(k, e)
+	return nil
+}
+
+func setInt(property reflect.Value, opts tagOptions, values []string, size int) error {
 	switch property.Interface().(type) {
 	case time.Duration:
-		d, err := time.ParseDuration(values[0])
+		d, err := parseDuration(values[0], opts)
 		if err != nil {
 			return err
 		}
 		property.SetInt(int64(d))
 	default:
+		if opts.has("bytesize") {
+			b, err := parseByteSize(values[0])
+			if err != nil {
+				return err
+			}
+			if b > 1<<(size-1)-1 {
+				return fmt.Errorf("byte size %q overflows int%d", values[0], size)
+			}
+			property.SetInt(int64(b))
+			return nil
+		}
+
 		v, err := strconv.ParseInt(values[0], 10, size)
 		if err != nil {
-			return err
+			return overflowError("int", size, values[0], err)
 		}
 		property.SetInt(v)
 	}
 	return nil
 }
 
-func setUInt(property reflect.Value, values []string, size int) error {
+func setUInt(property reflect.Value, opts tagOptions, values []string, size int) error {
+	if opts.has("bytesize") {
+		b, err := parseByteSize(values[0])
+		if err != nil {
+			return err
+		}
+		if size < 64 && b > 1<<size-1 {
+			return fmt.Errorf("byte size %q overflows uint%d", values[0], size)
+		}
+		property.SetUint(b)
+		return nil
+	}
+
 	ui, err := strconv.ParseUint(values[0], 10, size)
 	if err != nil {
-		return err
+		return overflowError("uint", size, values[0], err)
 	}
 	property.SetUint(ui)
 	return nil
 }
 
-func setBool(property reflect.Value, values []string) error {
-	b, err := strconv.ParseBool(values[0])
-	if err != nil {
+// overflowError explains range errors of integer parsing with the integer
+// type of the given bit size, e.g. int64, other errors are returned as is.
+func overflowError(kind string, size int, value string, err error) error {
+	if !errors.Is(err, strconv.ErrRange) {
 		return err
 	}
-	property.SetBool(b)
+	return fmt.Errorf("value %s overflows %s%d: %w", value, kind, size, err)
+}
+
+// setBool matches the value case-insensitively against the space separated
+// literals of the truthy and falsy options, which default to the literals of
+// strconv.ParseBool plus yes/on and no/off.
+func setBool(property reflect.Value, opts tagOptions, values []string) error {
+	truthy, ok := opts.lookup("truthy")
+	if !ok {
+		truthy = "1 t true yes on"
+	}
+	falsy, ok := opts.lookup("falsy")
+	if !ok {
+		falsy = "0 f false no off"
+	}
+
+	matches := func(literals string) bool {
+		return slices.ContainsFunc(strings.Fields(literals), func(l string) bool {
+			return strings.EqualFold(l, values[0])
+		})
+	}
+	switch {
+	case matches(truthy):
+		property.SetBool(true)
+	case matches(falsy):
+		property.SetBool(false)
+	default:
+		return &strconv.NumError{Func: "ParseBool", Num: values[0], Err: strconv.ErrSyntax}
+	}
 	return nil
 }
 
@@ -162,6 +645,43 @@ func setFloat(property reflect.Value, values []string, size int) error {
 	return nil
 }
 
+func setUnixTime(property reflect.Value, unit, value string) error {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var t time.Time
+	switch unit {
+	case "", "s":
+		t = time.Unix(n, 0)
+	case "ms":
+		t = time.UnixMilli(n)
+	case "us":
+		t = time.UnixMicro(n)
+	case "ns":
+		t = time.Unix(0, n)
+	default:
+		return fmt.Errorf("unknown time unit %q", unit)
+	}
+	property.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func setComplex(property reflect.Value, values []string, size int) error {
+	c, err := strconv.ParseComplex(values[0], size)
+	if err != nil {
+		return err
+	}
+	property.SetComplex(c)
+	return nil
+}
+
 type Valuer interface {
 	values() []string
 }
@@ -171,6 +691,14 @@ func Value(v ...string) Valuer {
 	return values(v)
 }
 
+// valuesOf returns the values of v, which may be nil.
+func valuesOf(v Valuer) []string {
+	if v == nil {
+		return nil
+	}
+	return v.values()
+}
+
 type values []string
 
 func (v values) values() []string {
@@ -181,67 +709,924 @@ func (v values) values() []string {
 //
 // Tag contains the field tag name
 // Get is a function to get the value/values for your given field.
+// GetCtx is used instead of Get if set and receives the context given to ToContext.
 type Source struct {
 	Tag string
-	Get func(string) (Valuer, error)
-}
+	// Tags lets the source match several field tags, e.g. "json" and
+	// "yaml". Tag, if set, is checked first, then Tags in order. The first
+	// tag found on a field is used.
+	Tags   []string
+	Get    func(string) (Valuer, error)
+	GetCtx func(context.Context, string) (Valuer, error)
+	// GetAll returns the values of several fields at once, e.g. from a
+	// parsed document. If set, it is called once per call to To with all
+	// fields of the struct and preferred over Get and GetCtx. Fields missing
+	// in the result are left unset.
+	GetAll func(context.Context, []string) (map[string]Valuer, error)
+	// Priority orders the sources of a field. A field set by a source is not
+	// overwritten by sources of lower priority, sources of equal priority
+	// are applied in slice order, so the last one wins.
+	Priority int
+	// Optional sources don't abort filling if asking them fails, the error
+	// is passed to the OnIgnoredError function and the field is filled from
+	// the other sources.
+	Optional bool
+	// UseFieldNameIfNoTag asks the source for fields without any of its
+	// tags by their field name, passed through NameTransform if set, e.g.
+	// strings.ToUpper. Nested structs without a tag stay sections, structs
+	// converted as a single value, like url.URL, are asked for by name.
+	UseFieldNameIfNoTag bool
+	NameTransform       func(string) string
+	// Prefix is put in front of every key the source is asked for, e.g.
+	// "APP_" turns `env:"PORT"` into "APP_PORT".
+	Prefix string
+	// KeyTransform, if set, normalizes every key before the source is asked
+	// for it, e.g. strings.ToUpper for sources with upper case keys.
+	KeyTransform func(string) string
+	// Transform, if set, preprocesses every value of the source before the
+	// transforms of the field tag, e.g. to trim whitespace. It is passed the
+	// key the value was returned for.
+	Transform func(field, value string) string
 
-type Sources []Source
-
-func From(sources []Source) Sources {
-	return sources
+	snapshot bool
 }
 
-// To takes the given sources and try to fill the fields of the given struct.
-func (sources Sources) To(obj interface{}) error {
-	if obj == nil {
-		return errors.New("given struct to fill is nil")
+// id returns the tag identifying the source, the prefixes of nested sections
+// are kept per id.
+func (source Source) id() string {
+	if source.Tag == "" && len(source.Tags) > 0 {
+		return source.Tags[0]
 	}
+	return source.Tag
+}
 
-	if len(sources) == 0 {
-		return nil
+// tagNames returns the field tags the source matches.
+func (source Source) tagNames() []string {
+	if source.Tag == "" {
+		return source.Tags
 	}
+	return append([]string{source.Tag}, source.Tags...)
+}
 
-	valueOf := reflect.ValueOf(obj)
-	for valueOf.Kind() == reflect.Ptr {
-		valueOf = valueOf.Elem()
+// key returns the key the source is asked for the field name, within a
+// section of the given prefixes.
+func (source Source) key(prefixes map[string]string, name string) string {
+	key := source.Prefix + prefixes[source.id()] + name
+	if source.KeyTransform != nil {
+		key = source.KeyTransform(key)
 	}
+	return key
+}
 
-	t := valueOf.Type()
-	for i := 0; i < valueOf.NumField(); i++ {
-		for _, source := range sources {
-			field := t.Field(i)
+func (source Source) get(ctx context.Context, field string) (Valuer, error) {
+	switch {
+	case source.GetCtx != nil:
+		return source.GetCtx(ctx, field)
+	case source.Get != nil:
+		return source.Get(field)
+	default:
+		return nil, fmt.Errorf("source %q has no get function", source.id())
+	}
+}
 
-			tagValue, ok := field.Tag.Lookup(source.Tag)
-			if !ok {
-				continue
-			}
+// Sources holds the sources and the options which are used to fill a struct.
+type Sources struct {
+	sources        []Source
+	emptySentinels []string
+	schema         reflect.Type
+	onIgnoredError func(error)
+	onUnfilled     func(string)
+	requiredGroups []string
+	maxDepth       *int
+	report         *[]Coercion
+	features       []string
+	errs           *Errors
+	firstWins      bool
+	firstNonEmpty  bool
+	emptyAsZero    bool
+	unexported     bool
+	requireSources bool
+	reset          bool
+	concurrency    int
+	unmarshal      func([]byte, interface{}) error
+}
 
-			property := valueOf.Field(i)
-			if !property.IsValid() || !property.CanSet() {
-				continue
-			}
+// From returns the given sources without options. It replaces converting a
+// []Source into Sources, which used to be a slice.
+func From(sources []Source) Sources {
+	return Sources{sources: sources}
+}
 
-			var values []string
-			v, err := source.Get(tagValue)
+// With returns a copy of the sources with source added last.
+func (sources Sources) With(source Source) Sources {
+	sources.sources = append(slices.Clip(sources.sources), source)
+	return sources
+}
 
-			if v != nil {
-				values = v.values()
-			}
+// Merge returns a copy of the sources with the sources of other added last.
+// The options of other are not taken over.
+func (sources Sources) Merge(other Sources) Sources {
+	sources.sources = append(slices.Clip(sources.sources), other.sources...)
+	return sources
+}
 
-			if err != nil {
-				return newError(tagValue, source.Tag, values, err)
-			}
+// EmptySentinels returns a copy of the sources which treats the given values
+// as absent. Values matching a sentinel are dropped after Get, a field whose
+// values are all dropped is left unset.
+func (sources Sources) EmptySentinels(sentinels ...string) Sources {
+	sources.emptySentinels = sentinels
+	return sources
+}
 
-			if len(values) == 0 {
-				continue
-			}
+// OnIgnoredError returns a copy of the sources which passes errors to fn that
+// don't abort filling, e.g. of fields with the tag option `onerror=zero`.
+func (sources Sources) OnIgnoredError(fn func(err error)) Sources {
+	sources.onIgnoredError = fn
+	return sources
+}
 
-			err = setValue(property, values...)
+func (sources Sources) ignoreError(err error) {
+	if sources.onIgnoredError != nil {
+		sources.onIgnoredError(err)
+	}
+}
+
+// OnUnfilled returns a copy of the sources which passes the path of each
+// settable field to fn that no source or default filled and that is still
+// zero, e.g. to spot typos in tags. It doesn't change which errors are
+// returned.
+func (sources Sources) OnUnfilled(fn func(field string)) Sources {
+	sources.onUnfilled = fn
+	return sources
+}
+
+// RequireSources returns a copy of the sources which returns ErrNoSources
+// from To if no sources are given, instead of leaving the struct untouched.
+func (sources Sources) RequireSources() Sources {
+	sources.requireSources = true
+	return sources
+}
+
+// RequireGroup returns a copy of the sources which requires the fields of each
+// given group to be filled all together or not at all. Fields join a group
+// with the tag option `group=name`.
+func (sources Sources) RequireGroup(groups ...string) Sources {
+	sources.requiredGroups = append(slices.Clip(sources.requiredGroups), groups...)
+	return sources
+}
+
+// checkGroups returns an error if a required group is only partially filled.
+func (sources Sources) checkGroups(t reflect.Type, tags []*fieldTag, filled []bool) error {
+	for _, group := range sources.requiredGroups {
+		var set, missing []string
+		for i, tag := range tags {
+			if sources.group(tag) != group {
+				continue
+			}
+			if filled[i] {
+				set = append(set, t.Field(i).Name)
+			} else {
+				missing = append(missing, t.Field(i).Name)
+			}
+		}
+
+		if len(set) > 0 && len(missing) > 0 {
+			return fmt.Errorf("group %q is partially filled: set %s, missing %s",
+				group, strings.Join(set, ", "), strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// group returns the group option of a field.
+func (sources Sources) group(tag *fieldTag) string {
+	group, _ := sources.option(tag, "group")
+	return group
+}
+
+// option looks up an option of a field, given either in the tag of a source
+// or as a tag of its own.
+func (sources Sources) option(tag *fieldTag, name string) (string, bool) {
+	for _, source := range sources.sources {
+		if _, _, opts, ok := tag.parseSource(source); ok {
+			if value, ok := opts.lookup(name); ok {
+				return value, true
+			}
+		}
+	}
+	return tag.Lookup(name)
+}
+
+// EnableFeatures returns a copy of the sources which enables the given
+// features. Fields with the tag option `feature=name` are only filled if
+// their feature is enabled, otherwise they are skipped as if no source
+// matched.
+func (sources Sources) EnableFeatures(features ...string) Sources {
+	sources.features = append(slices.Clip(sources.features), features...)
+	return sources
+}
+
+// enabled reports whether the feature of a field, if any, is enabled.
+func (sources Sources) enabled(tag *fieldTag) bool {
+	feature, ok := sources.option(tag, "feature")
+	return !ok || slices.Contains(sources.features, feature)
+}
+
+// FirstWins returns a copy of the sources which doesn't ask further sources
+// for a field once a source has set it. By default the last source wins.
+func (sources Sources) FirstWins() Sources {
+	sources.firstWins = true
+	return sources
+}
+
+// FirstNonEmpty returns a copy of the sources which asks the sources of a
+// field in order until one returns a non-empty value, like FirstWins with
+// empty strings treated as absent. Sources returning "" fall through to the
+// next source, e.g. from env to a config file.
+func (sources Sources) FirstNonEmpty() Sources {
+	sources.firstWins, sources.firstNonEmpty = true, true
+	return sources
+}
+
+// ResetBeforeFill returns a copy of the sources which sets every field any
+// source has a tag for to its zero value before filling it, e.g. to reload
+// config into the same struct without keeping values of removed keys. Other
+// fields keep their values.
+func (sources Sources) ResetBeforeFill() Sources {
+	sources.reset = true
+	return sources
+}
+
+// referenced reports whether any source is asked for the field itself.
+func (sources Sources) referenced(tag *fieldTag) bool {
+	for _, source := range sources.sources {
+		if tag.excludes(source) {
+			continue
+		}
+		if _, _, opts, ok := tag.parseSource(source); ok && !opts.has("prefix") {
+			return true
+		}
+	}
+	return false
+}
+
+// EmptyAsZero returns a copy of the sources which sets a field to its zero
+// value if a source returns a single empty string, instead of failing to
+// convert it. Sources returning no values at all still leave the field unset,
+// like values dropped by EmptySentinels.
+func (sources Sources) EmptyAsZero() Sources {
+	sources.emptyAsZero = true
+	return sources
+}
+
+// MaxDepth returns a copy of the sources which fills nested sections down to
+// the given depth only, deeper levels are left unset. A depth of 0 disables
+// nested sections.
+func (sources Sources) MaxDepth(depth int) Sources {
+	sources.maxDepth = &depth
+	return sources
+}
+
+func (sources Sources) depthLimit() int {
+	if sources.maxDepth == nil {
+		return defaultMaxDepth
+	}
+	return *sources.maxDepth
+}
+
+// UsingSchema returns a copy of the sources which takes the field tags from
+// the given schema struct instead of the struct to fill. Fields are matched by
+// name, every field of the schema has to exist in the struct to fill.
+func (sources Sources) UsingSchema(schema interface{}) Sources {
+	sources.schema = reflect.TypeOf(schema)
+	for sources.schema != nil && sources.schema.Kind() == reflect.Ptr {
+		sources.schema = sources.schema.Elem()
+	}
+	return sources
+}
+
+// UsingUnmarshal returns a copy of the sources which decodes struct fields,
+// slices and maps of structs and merge patches with the given function
+// instead of json.Unmarshal, e.g. to decode YAML.
+func (sources Sources) UsingUnmarshal(unmarshal func(data []byte, v interface{}) error) Sources {
+	sources.unmarshal = unmarshal
+	return sources
+}
+
+func (sources Sources) dropEmpty(values []string) []string {
+	if len(sources.emptySentinels) == 0 && !sources.firstNonEmpty {
+		return values
+	}
+
+	filtered := make([]string, 0, len(values))
+	for _, v := range values {
+		if !slices.Contains(sources.emptySentinels, v) && (v != "" || !sources.firstNonEmpty) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// To takes the given sources and try to fill the fields of the given struct.
+func (sources Sources) To(obj interface{}) error {
+	return sources.ToContext(context.Background(), obj)
+}
+
+// MustTo is like To but panics if the struct can't be filled. It simplifies
+// loading config into package variables at init time.
+func (sources Sources) MustTo(obj interface{}) {
+	if err := sources.To(obj); err != nil {
+		panic(err)
+	}
+}
+
+// ToContext is like To but passes ctx to the GetCtx function of the sources.
+// Filling is aborted with an error wrapping ctx.Err() once ctx is done.
+func (sources Sources) ToContext(ctx context.Context, obj interface{}) error {
+	valueOf, err := target(obj)
+	if err != nil {
+		return err
+	}
+
+	if len(sources.sources) == 0 {
+		if sources.requireSources {
+			return ErrNoSources
+		}
+		return nil
+	}
+
+	tags, err := schemaTags(valueOf.Type(), sources.schema)
+	if err != nil {
+		return err
+	}
+
+	sources, err = sources.takeSnapshots(ctx, valueOf.Type(), tags)
+	if err != nil {
+		return err
+	}
+
+	sources, err = sources.prefetch(ctx, valueOf.Type(), tags)
+	if err != nil {
+		return err
+	}
+	sources.sources = prioritized(sources.sources)
+
+	_, err = sources.fillStruct(ctx, valueOf, tags, scope{path: []reflect.Type{valueOf.Type()}, schema: sources.schema})
+	return err
+}
+
+// target returns the struct obj points to.
+func target(obj interface{}) (reflect.Value, error) {
+	if obj == nil {
+		return reflect.Value{}, errors.New("given struct to fill is nil")
+	}
+
+	valueOf := reflect.ValueOf(obj)
+	if valueOf.Kind() != reflect.Ptr {
+		return reflect.Value{}, InvalidTargetError{Type: valueOf.Type()}
+	}
+	for valueOf.Kind() == reflect.Ptr {
+		if valueOf.IsNil() {
+			return reflect.Value{}, errors.New("given struct to fill is nil")
+		}
+		valueOf = valueOf.Elem()
+	}
+	if valueOf.Kind() != reflect.Struct {
+		return reflect.Value{}, InvalidTargetError{Type: reflect.TypeOf(obj)}
+	}
+	return valueOf, nil
+}
+
+// prioritized returns the sources ordered by descending priority, sources of
+// equal priority keep their order.
+func prioritized(sources []Source) []Source {
+	if !slices.ContainsFunc(sources, func(source Source) bool { return source.Priority != 0 }) {
+		return sources
+	}
+
+	sorted := slices.Clone(sources)
+	slices.SortStableFunc(sorted, func(a, b Source) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	return sorted
+}
+
+// defaultMaxDepth limits how deep sections of nested structs are filled if
+// no MaxDepth is given.
+const defaultMaxDepth = 32
+
+// scope describes the position of a struct while walking nested structs.
+type scope struct {
+	// prefixes holds the key prefix of each source.
+	prefixes map[string]string
+	// path holds the struct types from the root down to the struct.
+	path []reflect.Type
+	// root is the struct fields of an embedded struct are promoted to and
+	// index the position of the embedded struct within root.
+	root  reflect.Type
+	index []int
+	// names holds the names of the struct fields from the root down to the
+	// struct.
+	names []string
+	// schema is the struct the field tags are taken from, nil if the tags of
+	// the struct itself are used.
+	schema reflect.Type
+}
+
+// fieldPath returns the dotted path of a field of the struct, e.g.
+// "Database.Port".
+func (s scope) fieldPath(name string) string {
+	if len(s.names) == 0 {
+		return name
+	}
+	return strings.Join(s.names, ".") + "." + name
+}
+
+// promoted reports whether the field at index i with the given name is
+// accessible from the struct it is promoted to. Like in Go, fields are
+// hidden by fields of the same name on a lower depth, fields of the same
+// name on the same depth hide each other.
+func (s scope) promoted(name string, i int) bool {
+	if s.root == nil {
+		return true
+	}
+	field, ok := s.root.FieldByName(name)
+	return ok && slices.Equal(field.Index, append(slices.Clip(s.index), i))
+}
+
+// nested returns the scope of the struct field at index i of t.
+func (s scope) nested(t reflect.Type, field reflect.StructField, prefixes map[string]string) scope {
+	next := scope{prefixes: prefixes, path: s.path, names: append(slices.Clip(s.names), field.Name), schema: nestedSchema(s.schema, field.Name)}
+	if field.Anonymous {
+		next.root, next.index = t, []int{field.Index[0]}
+		if s.root != nil {
+			next.root, next.index = s.root, append(slices.Clip(s.index), field.Index[0])
+		}
+	}
+	return next
+}
+
+// fillStruct fills the fields of the struct v and reports whether any field
+// was set.
+func (sources Sources) fillStruct(ctx context.Context, v reflect.Value, tags []*fieldTag, s scope) (bool, error) {
+	t := v.Type()
+	filled := make([]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if err := ctx.Err(); err != nil {
+			return false, fmt.Errorf("filling aborted: %w", err)
+		}
+
+		field := t.Field(i)
+		if !s.promoted(field.Name, i) || !sources.enabled(tags[i]) {
+			continue
+		}
+
+		// unexported embedded structs are walked for their exported fields
+		property := v.Field(i)
+		if !property.CanSet() && !field.Anonymous && sources.unexported && property.CanAddr() {
+			property = settable(property)
+		}
+		if !property.CanSet() && !field.Anonymous {
+			continue
+		}
+
+		if sources.reset && property.CanSet() && sources.referenced(tags[i]) {
+			property.Set(reflect.Zero(property.Type()))
+		}
+
+		var (
+			failed   bool
+			priority int
+		)
+		for _, source := range sources.sources {
+			if !property.CanSet() || filled[i] && (sources.firstWins || source.Priority < priority) {
+				break
+			}
+
+			ok, err := sources.fill(ctx, source, property, tags[i], s.prefixes, s.fieldPath(field.Name))
+			if err != nil {
+				if err := sources.collect(err); err != nil {
+					return false, err
+				}
+				failed = true
+			}
+			if ok {
+				filled[i], priority = true, source.Priority
+			}
+		}
+
+		var defaulted bool
+		if !filled[i] && !failed && property.CanSet() {
+			var err error
+			defaulted, err = sources.fillDefault(property, field.Name, tags[i], s.fieldPath(field.Name))
+			if err != nil {
+				if err := sources.collect(err); err != nil {
+					return false, err
+				}
+				failed = true
+			}
+		}
+
+		sectionPrefixes, section := sources.section(property.Type(), tags[i], s.prefixes)
+		if section && len(s.path) <= sources.depthLimit() {
+			ok, err := sources.excluding(tags[i]).fillSection(ctx, property, s.nested(t, field, sectionPrefixes))
 			if err != nil {
-				return newError(tagValue, source.Tag, values, err)
+				if err := sources.collect(err); err != nil {
+					return false, err
+				}
+				failed = true
+			}
+			filled[i] = filled[i] || ok
+		}
+
+		if !filled[i] && !defaulted && !failed && sources.required(tags[i]) {
+			err := sources.requiredError(field, tags[i], s)
+			if err := sources.collect(err); err != nil {
+				return false, err
 			}
 		}
+
+		if sources.onUnfilled != nil && !filled[i] && !defaulted && !failed && !section && property.CanSet() && property.IsZero() {
+			sources.onUnfilled(s.fieldPath(field.Name))
+		}
+	}
+
+	if err := sources.checkGroups(t, tags, filled); err != nil {
+		if err := sources.collect(err); err != nil {
+			return false, err
+		}
+	}
+	return slices.Contains(filled, true), nil
+}
+
+// section reports whether a field is a section of nested fields. This is the
+// case if a source tag has the option `prefix`, the name of such a tag is
+// added to the key prefix of its source. Struct fields without any matching
+// tag are sections as well.
+func (sources Sources) section(t reflect.Type, tag *fieldTag, prefixes map[string]string) (map[string]string, bool) {
+	var (
+		sectionPrefixes map[string]string
+		matched         bool
+	)
+	for _, source := range sources.sources {
+		_, name, opts, ok := tag.parseSource(source)
+		if !ok {
+			continue
+		}
+		if !opts.has("prefix") {
+			matched = true
+			continue
+		}
+
+		if sectionPrefixes == nil {
+			sectionPrefixes = maps.Clone(prefixes)
+			if sectionPrefixes == nil {
+				sectionPrefixes = map[string]string{}
+			}
+		}
+		sectionPrefixes[source.id()] = prefixes[source.id()] + name
+	}
+
+	if sectionPrefixes != nil {
+		return sectionPrefixes, true
+	}
+	return prefixes, !matched && isNestedStruct(t)
+}
+
+// excluding returns a copy of the sources without the sources which exclude
+// the field by a tag of "-", e.g. to fill its section from the other sources.
+func (sources Sources) excluding(tag *fieldTag) Sources {
+	if !slices.ContainsFunc(sources.sources, tag.excludes) {
+		return sources
+	}
+	sources.sources = slices.DeleteFunc(slices.Clone(sources.sources), tag.excludes)
+	return sources
+}
+
+// isNestedStruct reports whether t is a struct, or a pointer to one, whose
+// fields can be filled. Structs which setValue converts as a single value
+// are not nested.
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		if _, ok := typeConverter(t); ok {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isLeafStruct(t)
+}
+
+// isLeafStruct reports whether setValue converts values of the struct type t
+// as a whole, like registered types, the types of the standard library it
+// parses and types implementing one of the unmarshal interfaces.
+func isLeafStruct(t reflect.Type) bool {
+	if _, ok := typeConverter(t); ok || isSQLNull(t) {
+		return true
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(big.Int{}), reflect.TypeOf(big.Float{}),
+		reflect.TypeOf(url.URL{}), reflect.TypeOf(net.IPNet{}):
+		return true
+	}
+
+	for _, u := range []reflect.Type{
+		reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*flag.Value)(nil)).Elem(),
+	} {
+		if reflect.PointerTo(t).Implements(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// fillSection fills the fields of a nested struct. A nil pointer is only
+// allocated if any of its fields was set. Structs whose type is already part
+// of the path are recursive and left unset.
+func (sources Sources) fillSection(ctx context.Context, property reflect.Value, s scope) (bool, error) {
+	switch property.Kind() {
+	case reflect.Struct:
+		t := property.Type()
+		if slices.Contains(s.path, t) {
+			return false, nil
+		}
+		s.path = append(slices.Clip(s.path), t)
+		tags, err := schemaTags(t, s.schema)
+		if err != nil {
+			return false, err
+		}
+		return sources.fillStruct(ctx, property, tags, s)
+	case reflect.Ptr:
+		if !property.IsNil() {
+			return sources.fillSection(ctx, property.Elem(), s)
+		}
+		if !property.CanSet() {
+			// nil pointer to an unexported embedded struct
+			return false, nil
+		}
+
+		p := reflect.New(property.Type().Elem())
+		ok, err := sources.fillSection(ctx, p.Elem(), s)
+		if ok && err == nil {
+			property.Set(p)
+		}
+		return ok, err
+	default:
+		return false, fmt.Errorf("%w %q for prefix option", ErrUnsupportedKind, property.Kind())
+	}
+}
+
+func (sources Sources) hasSource(tag string) bool {
+	for _, source := range sources.sources {
+		if slices.Contains(source.tagNames(), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// fill sets property from the given source and reports whether it was set.
+// The prefix of the source is put in front of the key passed to it, path is
+// the path of the field reported by errors.
+func (sources Sources) fill(ctx context.Context, source Source, property reflect.Value, tag *fieldTag, prefixes map[string]string, path string) (bool, error) {
+	key, name, opts, ok := tag.parseSource(source)
+	if !ok || opts.has("prefix") {
+		return false, nil
+	}
+	name = source.key(prefixes, name)
+	opts.unmarshal = sources.unmarshal
+
+	fail := func(values []string, err error) Error {
+		e := newError(name, key, values, err)
+		e.Options, e.Path, e.Type = opts.all(), path, property.Type().String()
+		return e
+	}
+
+	if pinned, ok := opts.lookup("source"); ok {
+		if !sources.hasSource(pinned) {
+			return false, fail(nil, fmt.Errorf("unknown source %q", pinned))
+		}
+		if !slices.Contains(source.tagNames(), pinned) {
+			return false, nil
+		}
+	}
+
+	chain, err := opts.transformChain()
+	if err != nil {
+		return false, fail(nil, err)
+	}
+
+	v, err := source.get(ctx, name)
+	values := valuesOf(v)
+
+	if err != nil {
+		if source.Optional {
+			sources.ignoreError(fail(values, err))
+			return false, nil
+		}
+		return false, fail(values, err)
 	}
+
+	values = sources.dropEmpty(values)
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	if source.Transform != nil {
+		values = slices.Clone(values)
+		for i, value := range values {
+			values[i] = source.Transform(name, value)
+		}
+	}
+
+	transformed, err := applyTransforms(chain, values)
+	if err != nil {
+		return false, fail(values, err)
+	}
+	values = transformed
+
+	// convert into a copy, so the field stays untouched on errors
+	converted := reflect.New(property.Type()).Elem()
+	if !sources.emptyAsZero || len(values) != 1 || values[0] != "" {
+		converted.Set(property)
+		err = setValue(converted, opts, values...)
+		if err == nil {
+			err = checkConstraints(converted, opts)
+		}
+	}
+	if err == nil {
+		if opts.bool("keepzero", true) || !converted.IsZero() || property.IsZero() {
+			property.Set(converted)
+			sources.record(name, path, key, values, converted)
+		}
+		return true, nil
+	}
+
+	switch onError, _ := opts.lookup("onerror"); onError {
+	case "":
+		return false, fail(values, err)
+	case "zero":
+		property.Set(reflect.Zero(property.Type()))
+		sources.ignoreError(fail(values, err))
+		return true, nil
+	default:
+		return false, fail(values, fmt.Errorf("unknown onerror option %q", onError))
+	}
+}
+
+// fillDefault sets a field which was not filled by any source to the value of
+// its `default` option, if given. The value is converted with the options of
+// the first matching source tag.
+func (sources Sources) fillDefault(property reflect.Value, field string, tag *fieldTag, path string) (bool, error) {
+	value, ok := sources.option(tag, "default")
+	if !ok {
+		return false, nil
+	}
+
+	opts := tagOptions{tag: tag.tag}
+	for _, source := range sources.sources {
+		if _, _, sourceOpts, ok := tag.parseSource(source); ok {
+			opts = sourceOpts
+			break
+		}
+	}
+
+	opts.unmarshal = sources.unmarshal
+
+	converted := reflect.New(property.Type()).Elem()
+	converted.Set(property)
+	err := setValue(converted, opts, value)
+	if err == nil {
+		err = checkConstraints(converted, opts)
+	}
+	if err != nil {
+		e := newError(field, "default", []string{value}, err)
+		e.Options, e.Path, e.Type = opts.all(), path, property.Type().String()
+		return false, e
+	}
+	property.Set(converted)
+	sources.record(field, path, "default", []string{value}, converted)
+	return true, nil
+}
+
+// required reports whether a field has the option `required`.
+func (sources Sources) required(tag *fieldTag) bool {
+	value, ok := sources.option(tag, "required")
+	if !ok {
+		return false
+	}
+	required, err := strconv.ParseBool(value)
+	return value == "" || err == nil && required
+}
+
+// requiredError returns the error of the required field which wasn't filled.
+// It names the key and tag of the first source the field is tagged for.
+func (sources Sources) requiredError(field reflect.StructField, tag *fieldTag, s scope) Error {
+	name, key := field.Name, ""
+	for _, source := range sources.sources {
+		if k, n, opts, ok := tag.parseSource(source); ok && !opts.has("prefix") {
+			name, key = source.key(s.prefixes, n), k
+			break
+		}
+	}
+
+	err := newError(name, key, nil, errors.New("field is required but no source returned a value"))
+	err.Path, err.Type = s.fieldPath(field.Name), field.Type.String()
+	return err
+}
+
+// ToAll fills the given struct like To but doesn't stop at fields which fail
+// to be set. The errors of all fields are returned together as Errors.
+func (sources Sources) ToAll(obj interface{}) error {
+	var errs Errors
+	sources.errs = &errs
+	if err := sources.To(obj); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate fills a new zero value of the given struct's type like ToAll and
+// returns the errors of all fields, leaving the given struct untouched. It is
+// meant for checking sources before applying them, e.g. in a config lint
+// command.
+func (sources Sources) Validate(obj interface{}) error {
+	valueOf, err := target(obj)
+	if err != nil {
+		return err
+	}
+	return sources.ToAll(reflect.New(valueOf.Type()).Interface())
+}
+
+// collect adds err to the errors collected by ToAll and returns nil. Without
+// ToAll, err is returned as is to stop filling.
+func (sources Sources) collect(err error) error {
+	if sources.errs == nil {
+		return err
+	}
+	*sources.errs = append(*sources.errs, err)
 	return nil
 }
+
+// ToJSON fills the given struct like To and returns it encoded as JSON.
+// It is meant for tests, e.g. to compare the result against a golden file.
+func (sources Sources) ToJSON(obj interface{}) ([]byte, error) {
+	if err := sources.To(obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// schemaTags returns the struct tags of each field of t, taken from the
+// schema if one is given.
+func schemaTags(t, schema reflect.Type) ([]*fieldTag, error) {
+	if schema == nil {
+		return structTags(t), nil
+	}
+	tags := make([]*fieldTag, t.NumField())
+	for i := range tags {
+		tags[i] = newFieldTag(t.Field(i), "")
+	}
+
+	if schema.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema %s is not a struct", schema)
+	}
+
+	for i := 0; i < schema.NumField(); i++ {
+		schemaField := schema.Field(i)
+		field, ok := t.FieldByName(schemaField.Name)
+		if !ok || len(field.Index) != 1 {
+			return nil, fmt.Errorf("schema field %q does not exist in %s", schemaField.Name, t)
+		}
+		tags[field.Index[0]] = newFieldTag(field, schemaField.Tag)
+	}
+	return tags, nil
+}
+
+// nestedSchema returns the schema of the nested struct field name of the
+// given schema, nil if the schema has no such struct field. Nested structs
+// missing in the schema use their own tags.
+func nestedSchema(schema reflect.Type, name string) reflect.Type {
+	if schema == nil || schema.Kind() != reflect.Struct {
+		return nil
+	}
+	field, ok := schema.FieldByName(name)
+	if !ok || len(field.Index) != 1 {
+		return nil
+	}
+
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
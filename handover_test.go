@@ -8,8 +8,18 @@
 package handgover
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -73,7 +83,56 @@ func TestFillWithNilStruct(t *testing.T) {
 			},
 		},
 	}
-	assert.Error(t, From(sources).To(nil))
+	assert.EqualError(t, From(sources).To(nil), "given struct to fill is nil")
+
+	type config struct {
+		Foo string `foo:"bar"`
+	}
+	var c *config
+	assert.EqualError(t, From(sources).To(c), "given struct to fill is nil")
+	assert.EqualError(t, From(sources).To(&c), "given struct to fill is nil")
+}
+
+func TestFillWithInvalidTarget(t *testing.T) {
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("1"), nil
+			},
+		},
+	}
+
+	type config struct {
+		Foo int `foo:"bar"`
+	}
+
+	var (
+		i int
+		s config
+	)
+
+	tests := []struct {
+		obj      interface{}
+		expected string
+	}{
+		{obj: &i, expected: "given object of type *int is not a pointer to a struct"},
+		{obj: map[string]int{}, expected: "given object of type map[string]int is not a pointer to a struct"},
+		{obj: s, expected: "given object of type handgover.config is not a pointer to a struct"},
+	}
+
+	for _, test := range tests {
+		err := From(sources).To(test.obj)
+
+		var targetErr InvalidTargetError
+		assert.True(t, errors.As(err, &targetErr))
+		assert.EqualError(t, err, test.expected)
+	}
+	assert.Zero(t, s.Foo)
+
+	// the target is checked without sources as well
+	assert.Error(t, From(nil).To(&i))
 }
 
 func TestFillWithNoSource(t *testing.T) {
@@ -883,6 +942,153 @@ func TestFillFloat64WithInvalidValue(t *testing.T) {
 	assert.Equal(t, float64(1.5), s.Float64)
 }
 
+func TestFillUintptr(t *testing.T) {
+
+	var s struct {
+		Uintptr uintptr `foo:"bar"`
+	}
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("4096"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, uintptr(4096), s.Uintptr)
+}
+
+func TestFillUintptrWithInvalidValue(t *testing.T) {
+
+	var s struct {
+		Uintptr uintptr `foo:"bar"`
+	}
+	s.Uintptr = uintptr(1)
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("-1"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "bar", parsedErr.Field)
+	assert.Equal(t, "-1", parsedErr.Value)
+	assert.Error(t, parsedErr.InnerError)
+
+	assert.Equal(t, uintptr(1), s.Uintptr)
+}
+
+func TestFillComplex64(t *testing.T) {
+
+	var s struct {
+		Complex64 complex64 `foo:"bar"`
+	}
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("1.5+2i"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, complex64(1.5+2i), s.Complex64)
+}
+
+func TestFillComplex64WithInvalidValue(t *testing.T) {
+
+	var s struct {
+		Complex64 complex64 `foo:"bar"`
+	}
+	s.Complex64 = complex64(1.5 + 2i)
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "bar", parsedErr.Field)
+	assert.Equal(t, "invalid", parsedErr.Value)
+	assert.Error(t, parsedErr.InnerError)
+
+	assert.Equal(t, complex64(1.5+2i), s.Complex64)
+}
+
+func TestFillComplex128(t *testing.T) {
+
+	var s struct {
+		Complex128 complex128 `foo:"bar"`
+	}
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("1.5+2i"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, complex128(1.5+2i), s.Complex128)
+}
+
+func TestFillComplex128WithInvalidValue(t *testing.T) {
+
+	var s struct {
+		Complex128 complex128 `foo:"bar"`
+	}
+	s.Complex128 = complex128(1.5 + 2i)
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "bar", parsedErr.Field)
+	assert.Equal(t, "invalid", parsedErr.Value)
+	assert.Error(t, parsedErr.InnerError)
+
+	assert.Equal(t, complex128(1.5+2i), s.Complex128)
+}
+
 func TestFillStruct(t *testing.T) {
 
 	var s struct {
@@ -991,3 +1197,5063 @@ func TestFillIfSourceReturnsAnError(t *testing.T) {
 
 	assert.Equal(t, "hello world", s.String)
 }
+
+func TestFillWithEmptySentinels(t *testing.T) {
+
+	for _, sentinel := range []string{"<nil>", "null", ""} {
+		var s struct {
+			Int   int      `foo:"bar"`
+			Slice []string `foo:"baz"`
+		}
+		s.Int = 1
+		s.Slice = []string{"hello"}
+
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					if field == "baz" {
+						return Value(sentinel, sentinel), nil
+					}
+					return Value(sentinel), nil
+				},
+			},
+		}
+
+		assert.NoError(t, From(sources).EmptySentinels("<nil>", "null", "").To(&s), sentinel)
+		assert.Equal(t, 1, s.Int, sentinel)
+		assert.Equal(t, []string{"hello"}, s.Slice, sentinel)
+	}
+}
+
+func TestFillSliceWithEmptySentinels(t *testing.T) {
+
+	var s struct {
+		Slice []string `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("hello", "null", "world"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).EmptySentinels("null").To(&s))
+	assert.Equal(t, []string{"hello", "world"}, s.Slice)
+}
+
+func TestFillWithoutEmptySentinels(t *testing.T) {
+
+	var s struct {
+		String string `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("null"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "null", s.String)
+}
+
+func TestFillByteSize(t *testing.T) {
+
+	var s struct {
+		UInt64 uint64 `foo:"uint64,bytesize"`
+		Int    int    `foo:"int,bytesize"`
+		UInt32 uint32 `foo:"uint32,bytesize"`
+		Plain  int    `foo:"plain,bytesize"`
+	}
+
+	values := map[string]string{
+		"uint64": "10MB",
+		"int":    "512Ki",
+		"uint32": "1GiB",
+		"plain":  "42",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, uint64(10_000_000), s.UInt64)
+	assert.Equal(t, 512*1024, s.Int)
+	assert.Equal(t, uint32(1<<30), s.UInt32)
+	assert.Equal(t, 42, s.Plain)
+}
+
+func TestFillByteSizeWithInvalidValue(t *testing.T) {
+
+	for _, value := range []string{"10XB", "MB", "-1KB"} {
+		var s struct {
+			UInt64 uint64 `foo:"bar,bytesize"`
+		}
+		s.UInt64 = 1
+
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					assert.Equal(t, "bar", field)
+					return Value(value), nil
+				},
+			},
+		}
+
+		err := From(sources).To(&s)
+		assert.Error(t, err, value)
+
+		var parsedErr Error
+
+		assert.True(t, errors.As(err, &parsedErr), value)
+		assert.Equal(t, "bar", parsedErr.Field, value)
+		assert.Equal(t, value, parsedErr.Value, value)
+
+		assert.Equal(t, uint64(1), s.UInt64, value)
+	}
+}
+
+func TestFillByteSizeWithOverflow(t *testing.T) {
+
+	var s struct {
+		UInt8 uint8 `foo:"bar,bytesize"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("1KB"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "1KB", parsedErr.Value)
+}
+
+func TestFillByteSizeWithoutOption(t *testing.T) {
+
+	var s struct {
+		UInt64 uint64 `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("10MB"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+}
+
+func TestToJSON(t *testing.T) {
+
+	var s struct {
+		String string   `foo:"string" json:"string"`
+		Slice  []string `foo:"slice" json:"slice"`
+		Int    int      `foo:"int" json:"int"`
+	}
+
+	values := map[string][]string{
+		"string": {"hello"},
+		"slice":  {"hello", "world"},
+		"int":    {"1"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	b, err := From(sources).ToJSON(&s)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"string":"hello","slice":["hello","world"],"int":1}`, string(b))
+}
+
+func TestToJSONWithInvalidValue(t *testing.T) {
+
+	var s struct {
+		Int int `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	b, err := From(sources).ToJSON(&s)
+	assert.Error(t, err)
+	assert.Nil(t, b)
+}
+
+func TestFillUsingSchema(t *testing.T) {
+
+	type plain struct {
+		Host    string
+		Port    int
+		Ignored string
+	}
+
+	type schema struct {
+		Host string `foo:"host"`
+		Port int    `foo:"port"`
+	}
+
+	values := map[string]string{
+		"host": "localhost",
+		"port": "8080",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	p := plain{Ignored: "untouched"}
+	assert.NoError(t, From(sources).UsingSchema(schema{}).To(&p))
+	assert.Equal(t, plain{Host: "localhost", Port: 8080, Ignored: "untouched"}, p)
+
+	assert.NoError(t, From(sources).UsingSchema(&schema{}).To(&p))
+}
+
+func TestFillUsingSchemaWithNestedStructs(t *testing.T) {
+
+	type database struct {
+		Host string
+		Port int
+	}
+
+	type plain struct {
+		Name    string
+		DB      database
+		Replica *database
+	}
+
+	type databaseSchema struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	type schema struct {
+		Name    string          `env:"NAME"`
+		DB      databaseSchema  `env:"DB_,prefix"`
+		Replica *databaseSchema `env:"REPLICA_,prefix"`
+	}
+
+	values := map[string]string{
+		"NAME":         "app",
+		"DB_HOST":      "db",
+		"DB_PORT":      "5432",
+		"REPLICA_HOST": "replica",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	for _, sources := range []Sources{From(sources), From(sources).Concurrency(2)} {
+		var p plain
+		assert.NoError(t, sources.UsingSchema(schema{}).To(&p))
+		assert.Equal(t, plain{Name: "app", DB: database{Host: "db", Port: 5432}, Replica: &database{Host: "replica"}}, p)
+	}
+
+	// fields of nested schemas have to exist as well
+	type mismatch struct {
+		DB struct {
+			User string `env:"USER"`
+		} `env:"DB_,prefix"`
+	}
+	var p plain
+	assert.EqualError(t, From(sources).UsingSchema(mismatch{}).To(&p), `schema field "User" does not exist in handgover.database`)
+}
+
+func TestFillUsingSchemaWithMismatch(t *testing.T) {
+
+	type plain struct {
+		Host string
+	}
+
+	type schema struct {
+		Host string `foo:"host"`
+		Port int    `foo:"port"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("1"), nil
+			},
+		},
+	}
+
+	var p plain
+	assert.Error(t, From(sources).UsingSchema(schema{}).To(&p))
+	assert.Error(t, From(sources).UsingSchema("not a struct").To(&p))
+	assert.Equal(t, "", p.Host)
+}
+
+func TestFillSliceWithDelimiter(t *testing.T) {
+
+	var s struct {
+		Quoted    []string `foo:"list,quoted" delim:","`
+		Unquoted  []string `foo:"list" delim:","`
+		Semicolon []int    `foo:"ints,delim=;"`
+		Multi     []string `foo:"multi" delim:","`
+		Bytes     []byte   `foo:"list" delim:","`
+		Single    []string `foo:"list"`
+		Pointers  []*int   `foo:"ints" delim:";"`
+	}
+
+	values := map[string][]string{
+		"list":  {`a,"b,c",d`},
+		"ints":  {"1;2;3"},
+		"multi": {"a,b", "c"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"a", "b,c", "d"}, s.Quoted)
+	assert.Equal(t, []string{"a", `"b`, `c"`, "d"}, s.Unquoted)
+	assert.Equal(t, []int{1, 2, 3}, s.Semicolon)
+	assert.Equal(t, []string{"a,b", "c"}, s.Multi)
+	assert.Equal(t, []byte(`a,"b,c",d`), s.Bytes)
+	assert.Equal(t, []string{`a,"b,c",d`}, s.Single)
+	if assert.Len(t, s.Pointers, 3) {
+		assert.Equal(t, 3, *s.Pointers[2])
+	}
+}
+
+func TestFillSliceWithUnbalancedQuotes(t *testing.T) {
+
+	var s struct {
+		Slice []string `foo:"bar,quoted" delim:","`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value(`a,"b,c`), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "bar", parsedErr.Field)
+	assert.Equal(t, `a,"b,c`, parsedErr.Value)
+	assert.Nil(t, s.Slice)
+}
+
+type ctxKey struct{}
+
+type fakeSecretStore struct {
+	secrets map[string]string
+	err     error
+}
+
+func (f fakeSecretStore) GetSecret(ctx context.Context, name string) (string, bool, error) {
+	if ctx.Value(ctxKey{}) != "secret" {
+		return "", false, errors.New("context not propagated")
+	}
+	if f.err != nil {
+		return "", false, f.err
+	}
+	secret, ok := f.secrets[name]
+	return secret, ok, nil
+}
+
+func TestFillFromSecretSource(t *testing.T) {
+
+	var s struct {
+		Password string `secret:"db/password"`
+		Token    string `secret:"api/token"`
+	}
+	s.Token = "default"
+
+	store := fakeSecretStore{secrets: map[string]string{"db/password": "hunter2"}}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "secret")
+
+	assert.NoError(t, From([]Source{SecretSource(store)}).ToContext(ctx, &s))
+	assert.Equal(t, "hunter2", s.Password)
+	assert.Equal(t, "default", s.Token)
+}
+
+func TestFillFromSecretSourceWithError(t *testing.T) {
+
+	var s struct {
+		Password string `secret:"db/password"`
+	}
+
+	store := fakeSecretStore{err: errors.New("store unavailable")}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "secret")
+
+	err := From([]Source{SecretSource(store)}).ToContext(ctx, &s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "db/password", parsedErr.Field)
+	assert.Equal(t, "secret", parsedErr.Source)
+	assert.Equal(t, "store unavailable", parsedErr.InnerError.Error())
+}
+
+func TestFillWithoutGetFunction(t *testing.T) {
+
+	var s struct {
+		String string `foo:"bar"`
+	}
+
+	assert.Error(t, From([]Source{{Tag: "foo"}}).To(&s))
+}
+
+func TestFillWithTransforms(t *testing.T) {
+
+	RegisterTransform("reverse", func(s string) (string, error) {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	})
+
+	var s struct {
+		Lower   string   `foo:"lower,transform=trim|lower"`
+		Decoded string   `foo:"decoded,transform=base64decode|upper"`
+		Int     int      `foo:"int,transform=trim"`
+		Slice   []string `foo:"slice,transform=reverse"`
+	}
+
+	values := map[string][]string{
+		"lower":   {"  Hello World  "},
+		"decoded": {"aGVsbG8="},
+		"int":     {" 8080 "},
+		"slice":   {"abc", "def"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "hello world", s.Lower)
+	assert.Equal(t, "HELLO", s.Decoded)
+	assert.Equal(t, 8080, s.Int)
+	assert.Equal(t, []string{"cba", "fed"}, s.Slice)
+	assert.Equal(t, []string{"abc", "def"}, values["slice"])
+}
+
+func TestFillWithUnknownTransform(t *testing.T) {
+
+	var s struct {
+		String string `foo:"bar,transform=trim|unknown"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				t.Error("Get must not be called for an unknown transform")
+				return Value("hello"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "bar", parsedErr.Field)
+}
+
+func TestFillWithFailingTransform(t *testing.T) {
+
+	var s struct {
+		String string `foo:"bar,transform=base64decode"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("not base64!"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "not base64!", parsedErr.Value)
+}
+
+func TestFillWithOnErrorZero(t *testing.T) {
+
+	var s struct {
+		Int    int    `foo:"int,onerror=zero"`
+		String string `foo:"string"`
+	}
+	s.Int = 1
+
+	values := map[string]string{
+		"int":    "invalid",
+		"string": "hello",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	var ignored []error
+	err := From(sources).OnIgnoredError(func(err error) {
+		ignored = append(ignored, err)
+	}).To(&s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.Int)
+	assert.Equal(t, "hello", s.String)
+
+	assert.Len(t, ignored, 1)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(ignored[0], &parsedErr))
+	assert.Equal(t, "int", parsedErr.Field)
+	assert.Equal(t, "invalid", parsedErr.Value)
+}
+
+func TestFillWithUnknownOnError(t *testing.T) {
+
+	var s struct {
+		Int int `foo:"bar,onerror=ignore"`
+	}
+	s.Int = 1
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+	assert.Equal(t, 1, s.Int)
+}
+
+func TestFillFromRemoteConfigSource(t *testing.T) {
+
+	var s struct {
+		Host string `remote:"host"`
+		Port int    `remote:"port"`
+		Name string `remote:"name"`
+	}
+	s.Name = "default"
+
+	fetches := 0
+	source := RemoteConfigSource("remote", func(ctx context.Context) (map[string]string, error) {
+		fetches++
+		return map[string]string{"host": "localhost", "port": "8080"}, nil
+	}, time.Hour)
+
+	assert.NoError(t, From([]Source{source}).To(&s))
+	assert.NoError(t, From([]Source{source}).To(&s))
+
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "default", s.Name)
+}
+
+func TestRemoteConfigSourceRefreshesAfterTTL(t *testing.T) {
+
+	var s struct {
+		Port int `remote:"port"`
+	}
+
+	port := 8080
+	source := RemoteConfigSource("remote", func(ctx context.Context) (map[string]string, error) {
+		port++
+		return map[string]string{"port": strconv.Itoa(port)}, nil
+	}, 10*time.Millisecond)
+
+	assert.NoError(t, From([]Source{source}).To(&s))
+	assert.Equal(t, 8081, s.Port)
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.NoError(t, From([]Source{source}).To(&s))
+	assert.Equal(t, 8082, s.Port)
+}
+
+func TestRemoteConfigSourceWithFetchError(t *testing.T) {
+
+	var s struct {
+		Port int `remote:"port"`
+	}
+
+	source := RemoteConfigSource("remote", func(ctx context.Context) (map[string]string, error) {
+		return nil, errors.New("service unavailable")
+	}, time.Hour)
+
+	err := From([]Source{source}).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "service unavailable", parsedErr.InnerError.Error())
+}
+
+func TestFillSliceOfMapsFromJSON(t *testing.T) {
+
+	var s struct {
+		Records []map[string]int `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value(`[{"a": 1, "b": 2}, {"c": 3}]`), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []map[string]int{{"a": 1, "b": 2}, {"c": 3}}, s.Records)
+}
+
+func TestFillSliceOfStructsFromJSON(t *testing.T) {
+
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	var s struct {
+		Items    []item  `foo:"items"`
+		Pointers []*item `foo:"items"`
+		Multi    []item  `foo:"multi"`
+	}
+
+	values := map[string][]string{
+		"items": {`[{"id":1},{"id":2}]`},
+		"multi": {`{"id":3}`, `{"id":4}`},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []item{{ID: 1}, {ID: 2}}, s.Items)
+	assert.Equal(t, []*item{{ID: 1}, {ID: 2}}, s.Pointers)
+	assert.Equal(t, []item{{ID: 3}, {ID: 4}}, s.Multi)
+}
+
+func TestFillSliceOfMapsFromInvalidJSON(t *testing.T) {
+
+	var s struct {
+		Records []map[string]int `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value(`[{"a": 1}, {"b": "two"}]`), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, `[{"a": 1}, {"b": "two"}]`, parsedErr.Value)
+	assert.Contains(t, parsedErr.Error(), "element 1")
+	assert.Contains(t, parsedErr.Error(), "b")
+	assert.Nil(t, s.Records)
+}
+
+func TestFillWithKeepZeroFalse(t *testing.T) {
+
+	var s struct {
+		Int         int    `foo:"zero,keepzero=false"`
+		String      string `foo:"empty,keepzero=false"`
+		Bool        bool   `foo:"false,keepzero=false"`
+		Unset       int    `foo:"zero,keepzero=false"`
+		NonZero     int    `foo:"one,keepzero=false"`
+		KeepZero    int    `foo:"zero"`
+		KeepZeroToo int    `foo:"zero,keepzero=true"`
+	}
+	s.Int = 1
+	s.String = "hello"
+	s.Bool = true
+	s.NonZero = 2
+	s.KeepZero = 3
+	s.KeepZeroToo = 4
+
+	values := map[string]string{
+		"zero":  "0",
+		"empty": "",
+		"false": "false",
+		"one":   "1",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 1, s.Int)
+	assert.Equal(t, "hello", s.String)
+	assert.True(t, s.Bool)
+	assert.Equal(t, 0, s.Unset)
+	assert.Equal(t, 1, s.NonZero)
+	assert.Equal(t, 0, s.KeepZero)
+	assert.Equal(t, 0, s.KeepZeroToo)
+}
+
+func TestFillPointerWithInvalidValue(t *testing.T) {
+
+	var s struct {
+		Pointer *int `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				assert.Equal(t, "bar", field)
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+	assert.Nil(t, s.Pointer)
+}
+
+func TestFillWithRequiredGroup(t *testing.T) {
+
+	type config struct {
+		Cert string `foo:"cert,group=tls"`
+		Key  string `foo:"key,group=tls"`
+		Port int    `foo:"port"`
+	}
+
+	tests := []struct {
+		values map[string]string
+		err    bool
+	}{
+		{values: map[string]string{"cert": "cert.pem", "key": "key.pem"}},
+		{values: map[string]string{"port": "443"}},
+		{values: map[string]string{"cert": "cert.pem", "port": "443"}, err: true},
+	}
+
+	for _, test := range tests {
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					v, ok := test.values[field]
+					if !ok {
+						return nil, nil
+					}
+					return Value(v), nil
+				},
+			},
+		}
+
+		var c config
+		err := From(sources).RequireGroup("tls").To(&c)
+		if !test.err {
+			assert.NoError(t, err, test.values)
+			continue
+		}
+
+		assert.Error(t, err, test.values)
+		assert.Contains(t, err.Error(), `group "tls"`)
+		assert.Contains(t, err.Error(), "set Cert")
+		assert.Contains(t, err.Error(), "missing Key")
+
+		assert.NoError(t, From(sources).To(&c), "groups are only checked when required")
+	}
+}
+
+func TestFillExtendedDuration(t *testing.T) {
+
+	tests := map[string]time.Duration{
+		"1d":      24 * time.Hour,
+		"2w":      14 * 24 * time.Hour,
+		"1w2d3h":  (7+2)*24*time.Hour + 3*time.Hour,
+		"1.5d":    36 * time.Hour,
+		"-1d30m":  -(24*time.Hour + 30*time.Minute),
+		"90m":     90 * time.Minute,
+		"1h500ms": time.Hour + 500*time.Millisecond,
+	}
+
+	for value, expected := range tests {
+		var s struct {
+			Duration time.Duration `foo:"bar,duration=extended"`
+		}
+
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					return Value(value), nil
+				},
+			},
+		}
+
+		assert.NoError(t, From(sources).To(&s), value)
+		assert.Equal(t, expected, s.Duration, value)
+	}
+}
+
+func TestFillExtendedDurationWithInvalidValue(t *testing.T) {
+
+	for _, value := range []string{"1mo", "1y", "d", "1dd", "1.2.3d", ""} {
+		var s struct {
+			Duration time.Duration `foo:"bar,duration=extended"`
+		}
+
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					return Value(value), nil
+				},
+			},
+		}
+
+		err := From(sources).To(&s)
+		assert.Error(t, err, value)
+
+		var parsedErr Error
+
+		assert.True(t, errors.As(err, &parsedErr), value)
+		assert.Equal(t, value, parsedErr.Value, value)
+	}
+}
+
+func TestFillDurationWithoutExtendedOption(t *testing.T) {
+
+	var s struct {
+		Duration time.Duration `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("1d"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+}
+
+func TestFillWithPinnedSource(t *testing.T) {
+
+	var s struct {
+		Port    int `env:"PORT" file:"port" source:"env"`
+		Timeout int `env:"TIMEOUT" file:"timeout,source=env"`
+		Retries int `env:"RETRIES" file:"retries"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				if field == "TIMEOUT" {
+					return nil, nil
+				}
+				return Value("1"), nil
+			},
+		},
+		{
+			Tag: "file",
+			Get: func(field string) (Valuer, error) {
+				return Value("2"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 1, s.Port)
+	assert.Equal(t, 0, s.Timeout)
+	assert.Equal(t, 2, s.Retries)
+}
+
+func TestFillWithUnknownPinnedSource(t *testing.T) {
+
+	var s struct {
+		Port int `env:"PORT" source:"flags"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value("1"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown source "flags"`)
+	assert.Equal(t, 0, s.Port)
+}
+
+func TestFillStructWithMerge(t *testing.T) {
+
+	type database struct {
+		Host    string            `json:"host"`
+		Port    int               `json:"port"`
+		Options map[string]string `json:"options"`
+	}
+
+	var s struct {
+		Database database          `foo:"db,merge"`
+		Pointer  *database         `foo:"db,merge"`
+		Labels   map[string]string `foo:"labels,merge"`
+		Replaced database          `foo:"db"`
+	}
+	s.Database = database{Host: "localhost", Port: 5432, Options: map[string]string{"ssl": "on", "timeout": "5s"}}
+	s.Pointer = &database{Host: "localhost", Port: 5432}
+	s.Labels = map[string]string{"app": "handgover", "team": "core"}
+	s.Replaced = database{Host: "localhost", Port: 5432}
+
+	original := s.Pointer
+
+	values := map[string]string{
+		"db":     `{"port": 6543, "options": {"timeout": null, "mode": "ro"}}`,
+		"labels": `{"team": "platform", "app": null}`,
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, database{Host: "localhost", Port: 6543, Options: map[string]string{"ssl": "on", "mode": "ro"}}, s.Database)
+	assert.Equal(t, &database{Host: "localhost", Port: 6543, Options: map[string]string{"mode": "ro"}}, s.Pointer)
+	assert.Equal(t, map[string]string{"team": "platform"}, s.Labels)
+	assert.Equal(t, "", s.Replaced.Host)
+
+	assert.Equal(t, &database{Host: "localhost", Port: 5432}, original)
+}
+
+func TestFillStructWithMergeAndInvalidJSON(t *testing.T) {
+
+	var s struct {
+		Struct struct {
+			Hello string `json:"hello"`
+		} `foo:"bar,merge"`
+	}
+	s.Struct.Hello = "world"
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(`{ "hello" : invalidjson`), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, `{ "hello" : invalidjson`, parsedErr.Value)
+	assert.Equal(t, "world", s.Struct.Hello)
+}
+
+type level int8
+
+func TestFillWithKindParser(t *testing.T) {
+
+	RegisterKindParser(reflect.Bool, func(s string) (interface{}, error) {
+		switch s {
+		case "yes":
+			return true, nil
+		case "no":
+			return false, nil
+		default:
+			return nil, fmt.Errorf("invalid bool %q", s)
+		}
+	})
+	RegisterKindParser(reflect.Int8, func(s string) (interface{}, error) {
+		return int8(len(s)), nil
+	})
+	defer func() {
+		kindParsersMu.Lock()
+		delete(kindParsers, reflect.Bool)
+		delete(kindParsers, reflect.Int8)
+		kindParsersMu.Unlock()
+	}()
+
+	var s struct {
+		Bool    bool   `foo:"yes"`
+		Pointer *bool  `foo:"yes"`
+		Slice   []bool `foo:"slice"`
+		Level   level  `foo:"level"`
+		String  string `foo:"yes"`
+	}
+
+	values := map[string][]string{
+		"yes":   {"yes"},
+		"slice": {"no", "yes"},
+		"level": {"abc"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.True(t, s.Bool)
+	assert.True(t, *s.Pointer)
+	assert.Equal(t, []bool{false, true}, s.Slice)
+	assert.Equal(t, level(3), s.Level)
+	assert.Equal(t, "yes", s.String)
+
+	values["yes"] = []string{"true"}
+
+	err := From(sources).To(&s)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "true", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `invalid bool "true"`)
+}
+
+func TestFillWithKindParserOfWrongType(t *testing.T) {
+
+	RegisterKindParser(reflect.Int8, func(s string) (interface{}, error) {
+		return s, nil
+	})
+	defer func() {
+		kindParsersMu.Lock()
+		delete(kindParsers, reflect.Int8)
+		kindParsersMu.Unlock()
+	}()
+
+	var s struct {
+		Int8 int8 `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("1"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+}
+
+func TestFillSectionWithPrefix(t *testing.T) {
+
+	type server struct {
+		Host string `map:"host"`
+		Port int    `map:"port"`
+	}
+
+	type database struct {
+		Host    string  `map:"host"`
+		Port    int     `map:"port"`
+		Replica server  `map:"replica.,prefix"`
+		Backup  *server `map:"backup.,prefix"`
+	}
+
+	var c struct {
+		Host     string   `map:"host"`
+		Database database `map:"db.,prefix"`
+		Cache    *server  `map:"cache.,prefix"`
+		Unused   *server  `map:"unused.,prefix"`
+	}
+
+	values := map[string]string{
+		"host":              "app",
+		"db.host":           "db",
+		"db.port":           "5432",
+		"db.replica.host":   "replica",
+		"db.replica.port":   "5433",
+		"cache.host":        "cache",
+		"db.backup.missing": "ignored",
+	}
+
+	var keys []string
+	sources := []Source{
+		{
+			Tag: "map",
+			Get: func(field string) (Valuer, error) {
+				keys = append(keys, field)
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, "app", c.Host)
+	assert.Equal(t, database{Host: "db", Port: 5432, Replica: server{Host: "replica", Port: 5433}}, c.Database)
+	assert.Equal(t, &server{Host: "cache"}, c.Cache)
+	assert.Nil(t, c.Unused)
+
+	assert.Equal(t, []string{
+		"host",
+		"db.host", "db.port", "db.replica.host", "db.replica.port", "db.backup.host", "db.backup.port",
+		"cache.host", "cache.port",
+		"unused.host", "unused.port",
+	}, keys)
+}
+
+func TestFillSectionWithInvalidValue(t *testing.T) {
+
+	var c struct {
+		Database struct {
+			Port int `map:"port"`
+		} `map:"db.,prefix"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "map",
+			Get: func(field string) (Valuer, error) {
+				return Value("invalid"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&c)
+	assert.Error(t, err)
+
+	var parsedErr Error
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "db.port", parsedErr.Field)
+}
+
+func TestFillSectionOfUnsupportedKind(t *testing.T) {
+
+	var c struct {
+		Port int `map:"db.,prefix"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "map",
+			Get: func(field string) (Valuer, error) {
+				return Value("1"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&c))
+}
+
+type templateConfig struct {
+	APIKey   string        `env:"API_KEY,required"`
+	Port     int           `env:"PORT,default=8080"`
+	Name     string        `env:"NAME" default:"handgover"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Hosts    []string      `env:"HOSTS"`
+	Database struct {
+		Host string `env:"HOST"`
+	} `env:"DB_,prefix"`
+	Ignored string
+	secret  string `env:"SECRET"`
+}
+
+func TestTemplate(t *testing.T) {
+
+	assert.Equal(t, `# required
+API_KEY=
+PORT=8080
+NAME=handgover
+TIMEOUT=
+HOSTS=
+DB_HOST=
+`, Template(&templateConfig{}, "env", TemplateEnv))
+
+	assert.Equal(t, "API_KEY\nPORT\nNAME\nTIMEOUT\nHOSTS\nDB_HOST\n", Template(templateConfig{}, "env", TemplateKeys))
+
+	assert.JSONEq(t, `{
+		"API_KEY": "",
+		"PORT": 8080,
+		"NAME": "handgover",
+		"TIMEOUT": "0s",
+		"HOSTS": [],
+		"DB_HOST": ""
+	}`, Template(&templateConfig{}, "env", TemplateJSON))
+}
+
+func TestTemplateWithoutStruct(t *testing.T) {
+
+	assert.Equal(t, "", Template(nil, "env", TemplateEnv))
+	assert.Equal(t, "", Template("string", "env", TemplateEnv))
+	assert.Equal(t, "", Template(&templateConfig{}, "unknown", TemplateEnv))
+}
+
+type node struct {
+	Name  string `map:"name"`
+	Left  *node  `map:"left.,prefix"`
+	Right *node  `map:"right.,prefix"`
+}
+
+func TestFillRecursiveSection(t *testing.T) {
+
+	var c struct {
+		Root node `map:"root.,prefix"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "map",
+			Get: func(field string) (Valuer, error) {
+				return Value(field), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, node{Name: "root.name"}, c.Root)
+
+	var n node
+	assert.NoError(t, From(sources).To(&n))
+	assert.Equal(t, node{Name: "name"}, n)
+
+	assert.Equal(t, "name\n", Template(&n, "map", TemplateKeys))
+}
+
+func TestFillWithMaxDepth(t *testing.T) {
+
+	type inner struct {
+		Name string `map:"name"`
+	}
+
+	type outer struct {
+		Name  string `map:"name"`
+		Inner inner  `map:"inner.,prefix"`
+	}
+
+	var c struct {
+		Name  string `map:"name"`
+		Outer outer  `map:"outer.,prefix"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "map",
+			Get: func(field string) (Valuer, error) {
+				return Value(field), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).MaxDepth(1).To(&c))
+	assert.Equal(t, "name", c.Name)
+	assert.Equal(t, outer{Name: "outer.name"}, c.Outer)
+
+	assert.NoError(t, From(sources).MaxDepth(2).To(&c))
+	assert.Equal(t, "outer.inner.name", c.Outer.Inner.Name)
+
+	c.Outer = outer{}
+	assert.NoError(t, From(sources).MaxDepth(0).To(&c))
+	assert.Equal(t, outer{}, c.Outer)
+}
+
+type list struct {
+	Value string `env:"VALUE"`
+	Next  *list
+}
+
+func TestFillNestedStruct(t *testing.T) {
+
+	type server struct {
+		Host    string `env:"HOST"`
+		Port    int    `env:"PORT"`
+		private string `env:"PRIVATE"`
+	}
+
+	var c struct {
+		Server  server
+		Pointer *struct {
+			Debug bool `env:"DEBUG"`
+		}
+		Unused *struct {
+			Missing string `env:"MISSING"`
+		}
+		JSON     server `env:"JSON"`
+		Time     time.Time
+		List     list
+		internal server
+	}
+
+	values := map[string]string{
+		"HOST":    "localhost",
+		"PORT":    "8080",
+		"PRIVATE": "secret",
+		"DEBUG":   "true",
+		"JSON":    `{"Host": "json"}`,
+		"VALUE":   "first",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, server{Host: "localhost", Port: 8080}, c.Server)
+	assert.NotNil(t, c.Pointer)
+	assert.True(t, c.Pointer.Debug)
+	assert.Nil(t, c.Unused)
+	assert.Equal(t, server{Host: "json"}, c.JSON)
+	assert.True(t, c.Time.IsZero())
+	assert.Equal(t, list{Value: "first"}, c.List)
+	assert.Equal(t, server{}, c.internal)
+}
+
+func TestFillEmbeddedStruct(t *testing.T) {
+
+	type Exported struct {
+		Host string `env:"HOST"`
+		Name string `env:"EXPORTED_NAME"`
+	}
+
+	type unexported struct {
+		Port int    `env:"PORT"`
+		Name string `env:"UNEXPORTED_NAME"`
+	}
+
+	type Pointer struct {
+		Debug bool `env:"DEBUG"`
+	}
+
+	type Unused struct {
+		Missing string `env:"MISSING"`
+	}
+
+	var c struct {
+		Exported
+		unexported
+		*Pointer
+		*Unused
+		Name string `env:"NAME"`
+	}
+
+	values := map[string]string{
+		"HOST":            "localhost",
+		"PORT":            "8080",
+		"DEBUG":           "true",
+		"NAME":            "outer",
+		"EXPORTED_NAME":   "exported",
+		"UNEXPORTED_NAME": "unexported",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, "localhost", c.Host)
+	assert.Equal(t, 8080, c.Port)
+	assert.NotNil(t, c.Pointer)
+	assert.True(t, c.Debug)
+	assert.Nil(t, c.Unused)
+
+	// shadowed fields are not promoted
+	assert.Equal(t, "outer", c.Name)
+	assert.Empty(t, c.Exported.Name)
+	assert.Empty(t, c.unexported.Name)
+}
+
+func TestFillEmbeddedStructWithAmbiguousField(t *testing.T) {
+
+	type First struct {
+		Name string `env:"FIRST"`
+	}
+
+	type Second struct {
+		Name string `env:"SECOND"`
+		Port int    `env:"PORT"`
+	}
+
+	var c struct {
+		First
+		Second
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				if field == "PORT" {
+					return Value("8080"), nil
+				}
+				return Value(field), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Empty(t, c.First.Name)
+	assert.Empty(t, c.Second.Name)
+	assert.Equal(t, 8080, c.Port)
+}
+
+func TestFillBoolFromInteger(t *testing.T) {
+
+	var s struct {
+		True  bool   `foo:"true"`
+		False bool   `foo:"false"`
+		Slice []bool `foo:"slice,delim=;"`
+	}
+	s.False = true
+
+	values := map[string][]string{
+		"true":  {"1"},
+		"false": {"0"},
+		"slice": {"1;0;true"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.True(t, s.True)
+	assert.False(t, s.False)
+	assert.Equal(t, []bool{true, false, true}, s.Slice)
+
+	values["slice"] = []string{"1", "2"}
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "slice", parsedErr.Field)
+	assert.Equal(t, "2", parsedErr.Value)
+	assert.Contains(t, parsedErr.InnerError.Error(), "element 1")
+	assert.Equal(t, []bool{true, false, true}, s.Slice)
+}
+
+func TestFillFromSnapshotSource(t *testing.T) {
+
+	var c struct {
+		Host     string `remote:"host"`
+		Port     int    `remote:"port"`
+		Database struct {
+			Name string `remote:"name"`
+		} `remote:"db.,prefix"`
+		Local string `env:"LOCAL"`
+	}
+
+	var (
+		requested []string
+		calls     int
+	)
+	sources := []Source{
+		SnapshotSource(Source{
+			Tag: "remote",
+			Get: func(field string) (Valuer, error) {
+				t.Errorf("unexpected call of Get for %q", field)
+				return nil, nil
+			},
+			GetAll: func(_ context.Context, fields []string) (map[string]Valuer, error) {
+				calls++
+				requested = fields
+				return map[string]Valuer{
+					"host":    Value("localhost"),
+					"port":    Value("8080"),
+					"db.name": Value("app"),
+				}, nil
+			},
+		}),
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(field), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"host", "port", "db.name"}, requested)
+	assert.Equal(t, "localhost", c.Host)
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, "app", c.Database.Name)
+	assert.Equal(t, "LOCAL", c.Local)
+
+	sources[0].GetAll = func(context.Context, []string) (map[string]Valuer, error) {
+		return nil, errors.New("unavailable")
+	}
+	assert.EqualError(t, From(sources).To(&c), `failed to take snapshot of source "remote": unavailable`)
+}
+
+func TestFillFromSnapshotSourceWithoutGetAll(t *testing.T) {
+
+	var c struct {
+		Host string `remote:"host"`
+		Port int    `remote:"port"`
+	}
+
+	var requested []string
+	sources := []Source{
+		SnapshotSource(Source{
+			Tag: "remote",
+			Get: func(field string) (Valuer, error) {
+				requested = append(requested, field)
+				if field == "port" {
+					return nil, nil
+				}
+				return Value(field), nil
+			},
+		}),
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, []string{"host", "port"}, requested)
+	assert.Equal(t, "host", c.Host)
+	assert.Zero(t, c.Port)
+}
+
+func TestFillMap(t *testing.T) {
+
+	var s struct {
+		Labels  map[string]string                `foo:"labels"`
+		Ports   map[string]int                   `foo:"ports"`
+		Flags   map[string]bool                  `foo:"flags,delim=;"`
+		Nested  map[string][]int                 `foo:"nested"`
+		Servers map[string]struct{ Host string } `foo:"servers"`
+	}
+
+	values := map[string][]string{
+		"labels":  {`{"app": "web", "tier": "frontend"}`},
+		"ports":   {"http=80", "https=443"},
+		"flags":   {"debug=1;verbose=0;trace=true"},
+		"nested":  {`{"a": [1, 2]}`},
+		"servers": {`{"main": {"Host": "localhost"}}`},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend"}, s.Labels)
+	assert.Equal(t, map[string]int{"http": 80, "https": 443}, s.Ports)
+	assert.Equal(t, map[string]bool{"debug": true, "verbose": false, "trace": true}, s.Flags)
+	assert.Equal(t, map[string][]int{"a": {1, 2}}, s.Nested)
+	assert.Equal(t, "localhost", s.Servers["main"].Host)
+}
+
+func TestFillMapWithInvalidValue(t *testing.T) {
+
+	tests := []struct {
+		values  []string
+		value   string
+		message string
+	}{
+		{values: []string{"a=1", "b=x"}, value: "x", message: `key "b"`},
+		{values: []string{`{"a": 1, "b": "2", "c": true}`}, value: "true", message: `key "c"`},
+		{values: []string{"a"}, value: "a", message: "not of the form key=value"},
+	}
+
+	for _, test := range tests {
+		var s struct {
+			Map map[string]int `foo:"bar"`
+		}
+		s.Map = map[string]int{"old": 1}
+
+		sources := []Source{
+			{
+				Tag: "foo",
+				Get: func(field string) (Valuer, error) {
+					return Value(test.values...), nil
+				},
+			},
+		}
+
+		err := From(sources).To(&s)
+
+		var parsedErr Error
+		assert.True(t, errors.As(err, &parsedErr))
+		assert.Equal(t, test.value, parsedErr.Value)
+		assert.Contains(t, parsedErr.InnerError.Error(), test.message)
+		assert.Equal(t, map[string]int{"old": 1}, s.Map)
+	}
+}
+
+type color int
+
+func (c *color) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "red":
+		*c = 1
+	case "green":
+		*c = 2
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+func TestFillTextUnmarshaler(t *testing.T) {
+
+	var s struct {
+		IP      net.IP   `foo:"ip"`
+		Color   color    `foo:"color"`
+		Pointer *color   `foo:"color"`
+		Colors  []color  `foo:"colors" delim:","`
+		IPs     []net.IP `foo:"ips" delim:","`
+	}
+
+	values := map[string]string{
+		"ip":     "192.168.0.1",
+		"color":  "green",
+		"colors": "red,green",
+		"ips":    "127.0.0.1,::1",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, net.ParseIP("192.168.0.1"), s.IP)
+	assert.Equal(t, color(2), s.Color)
+	assert.Equal(t, color(2), *s.Pointer)
+	assert.Equal(t, []color{1, 2}, s.Colors)
+	assert.Equal(t, []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, s.IPs)
+
+	values["color"] = "blue"
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "color", parsedErr.Field)
+	assert.Equal(t, "blue", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `unknown color "blue"`)
+	assert.Equal(t, color(2), s.Color)
+}
+
+func TestToWithReport(t *testing.T) {
+
+	var s struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Debug   *bool         `env:"DEBUG"`
+		Hosts   []string      `env:"HOSTS"`
+		Missing string        `env:"MISSING"`
+	}
+
+	values := map[string][]string{
+		"PORT":    {"8080"},
+		"TIMEOUT": {"1m30s"},
+		"DEBUG":   {"1"},
+		"HOSTS":   {"a", "b"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	report, err := From(sources).ToWithReport(&s)
+	assert.NoError(t, err)
+	assert.Equal(t, []Coercion{
+		{Field: "PORT", Path: "Port", Source: "env", Value: "8080", Result: "8080"},
+		{Field: "TIMEOUT", Path: "Timeout", Source: "env", Value: "1m30s", Result: "1m30s"},
+		{Field: "DEBUG", Path: "Debug", Source: "env", Value: "1", Result: "true"},
+		{Field: "HOSTS", Path: "Hosts", Source: "env", Value: "[a b]", Result: "[a b]"},
+	}, report)
+
+	values["PORT"] = []string{"invalid"}
+	report, err = From(sources).ToWithReport(&s)
+	assert.Error(t, err)
+	assert.Empty(t, report)
+}
+
+func TestFillWithFeatures(t *testing.T) {
+
+	type config struct {
+		Name    string `env:"NAME"`
+		Preview bool   `env:"PREVIEW,feature=experimental"`
+		Beta    struct {
+			Limit int `env:"LIMIT"`
+		} `feature:"beta"`
+	}
+
+	values := map[string]string{
+		"NAME":    "app",
+		"PREVIEW": "true",
+		"LIMIT":   "10",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	var c config
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, config{Name: "app"}, c)
+
+	c = config{}
+	assert.NoError(t, From(sources).EnableFeatures("experimental").To(&c))
+	assert.Equal(t, "app", c.Name)
+	assert.True(t, c.Preview)
+	assert.Zero(t, c.Beta.Limit)
+
+	c = config{}
+	assert.NoError(t, From(sources).EnableFeatures("experimental").EnableFeatures("beta").To(&c))
+	assert.True(t, c.Preview)
+	assert.Equal(t, 10, c.Beta.Limit)
+}
+
+type blob struct {
+	received []byte
+}
+
+func (b *blob) UnmarshalBinary(data []byte) error {
+	b.received = data
+	return nil
+}
+
+type textBlob struct {
+	blob
+	text string
+}
+
+func (b *textBlob) UnmarshalText(text []byte) error {
+	b.text = string(text)
+	return nil
+}
+
+func TestFillBinaryUnmarshaler(t *testing.T) {
+
+	var s struct {
+		Blob     blob     `foo:"bar"`
+		Pointer  *blob    `foo:"bar"`
+		TextBlob textBlob `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("\x00\x01raw"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []byte("\x00\x01raw"), s.Blob.received)
+	assert.Equal(t, []byte("\x00\x01raw"), s.Pointer.received)
+
+	// text takes precedence over binary
+	assert.Equal(t, "\x00\x01raw", s.TextBlob.text)
+	assert.Nil(t, s.TextBlob.received)
+}
+
+func TestFillWithDefault(t *testing.T) {
+
+	var s struct {
+		Port    int           `env:"PORT" default:"8080"`
+		Host    string        `env:"HOST" default:"localhost"`
+		Size    uint64        `env:"SIZE,bytesize" default:"1KiB"`
+		Timeout time.Duration `env:"TIMEOUT,default=5s"`
+		Tags    []string      `env:"TAGS" default:"a,b" delim:","`
+		Server  struct {
+			Name string `env:"NAME" default:"main"`
+		}
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				if field == "HOST" {
+					return Value("example.com"), nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "example.com", s.Host)
+	assert.Equal(t, uint64(1024), s.Size)
+	assert.Equal(t, 5*time.Second, s.Timeout)
+	assert.Equal(t, []string{"a", "b"}, s.Tags)
+	assert.Equal(t, "main", s.Server.Name)
+}
+
+func TestFillWithInvalidDefault(t *testing.T) {
+
+	var s struct {
+		Port int `env:"PORT" default:"http"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "Port", parsedErr.Field)
+	assert.Equal(t, "default", parsedErr.Source)
+	assert.Equal(t, "http", parsedErr.Value)
+	assert.Zero(t, s.Port)
+}
+
+func TestFillFromRetrySource(t *testing.T) {
+
+	var s struct {
+		Host string `remote:"host"`
+	}
+
+	var calls int
+	failing := Source{
+		Tag: "remote",
+		Get: func(field string) (Valuer, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("unavailable")
+			}
+			return Value("localhost"), nil
+		},
+	}
+
+	assert.NoError(t, From([]Source{RetrySource(failing, 3, time.Millisecond)}).To(&s))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "localhost", s.Host)
+
+	calls = 0
+	s.Host = ""
+	err := From([]Source{RetrySource(failing, 2, time.Millisecond)}).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "unavailable")
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, s.Host)
+}
+
+func TestFillFromRetrySourceWithCanceledContext(t *testing.T) {
+
+	var s struct {
+		Host string `remote:"host"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	failing := Source{
+		Tag: "remote",
+		GetCtx: func(ctx context.Context, field string) (Valuer, error) {
+			calls++
+			cancel()
+			return nil, errors.New("unavailable")
+		},
+	}
+
+	err := From([]Source{RetrySource(failing, 5, time.Hour)}).ToContext(ctx, &s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, context.Canceled, parsedErr.InnerError)
+	assert.Equal(t, 1, calls)
+}
+
+func TestFillRequired(t *testing.T) {
+
+	type config struct {
+		APIKey  string `env:"API_KEY" flag:"api-key" required:"true"`
+		Port    int    `env:"PORT,required" default:"8080"`
+		Debug   bool   `env:"DEBUG" required:"false"`
+		Section struct {
+			Name string `env:"NAME"`
+		} `required:""`
+	}
+
+	values := map[string]string{}
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+		{
+			Tag: "flag",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	var c config
+	err := From(sources).To(&c)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "API_KEY", parsedErr.Field)
+	assert.Equal(t, "env", parsedErr.Source)
+	assert.Equal(t, "APIKey", parsedErr.Path)
+	assert.EqualError(t, err, `failed to set field "API_KEY" from source "env": field is required but no source returned a value`)
+
+	// any source satisfies the requirement
+	values["api-key"] = "secret"
+	err = From(sources).To(&c)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "Section", parsedErr.Field)
+	assert.EqualError(t, err, `failed to set field "Section": field is required but no source returned a value`)
+
+	values["NAME"] = "main"
+	c = config{}
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, "secret", c.APIKey)
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, "main", c.Section.Name)
+}
+
+func TestToAll(t *testing.T) {
+
+	var s struct {
+		Port    int           `env:"PORT"`
+		Host    string        `env:"HOST"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Key     string        `env:"KEY" required:"true"`
+		Server  struct {
+			Debug bool `env:"DEBUG"`
+		}
+	}
+
+	values := map[string]string{
+		"PORT":    "http",
+		"HOST":    "localhost",
+		"TIMEOUT": "soon",
+		"DEBUG":   "maybe",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	// To stops at the first error
+	err := From(sources).To(&s)
+	assert.EqualError(t, err, `failed to set field "PORT" from source "env": strconv.ParseInt: parsing "http": invalid syntax`)
+	assert.Empty(t, s.Host)
+
+	err = From(sources).ToAll(&s)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 4)
+	assert.Equal(t, "localhost", s.Host)
+
+	var fields []string
+	for _, err := range errs {
+		var parsedErr Error
+		assert.True(t, errors.As(err, &parsedErr))
+		fields = append(fields, parsedErr.Field)
+	}
+	assert.Equal(t, []string{"PORT", "TIMEOUT", "KEY", "DEBUG"}, fields)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "PORT", parsedErr.Field)
+
+	values = map[string]string{"KEY": "secret"}
+	assert.NoError(t, From(sources).ToAll(&s))
+}
+
+func TestFillWithPriority(t *testing.T) {
+
+	var s struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	source := func(priority int, values map[string]string) Source {
+		return Source{
+			Tag:      "env",
+			Priority: priority,
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		}
+	}
+
+	high := source(10, map[string]string{"HOST": "high"})
+	low := source(0, map[string]string{"HOST": "low", "PORT": "80"})
+	equal := source(0, map[string]string{"HOST": "equal", "PORT": "8080"})
+
+	// the highest priority wins, regardless of the order
+	assert.NoError(t, From([]Source{high, low}).To(&s))
+	assert.Equal(t, "high", s.Host)
+	assert.Equal(t, 80, s.Port)
+
+	assert.NoError(t, From([]Source{low, high}).To(&s))
+	assert.Equal(t, "high", s.Host)
+
+	// the last source wins on equal priority
+	assert.NoError(t, From([]Source{low, equal}).To(&s))
+	assert.Equal(t, "equal", s.Host)
+	assert.Equal(t, 8080, s.Port)
+
+	assert.NoError(t, From([]Source{equal, low}).To(&s))
+	assert.Equal(t, "low", s.Host)
+	assert.Equal(t, 80, s.Port)
+}
+
+func TestFillWithFirstWins(t *testing.T) {
+
+	var s struct {
+		Host string `env:"HOST" flag:"host"`
+		Port int    `env:"PORT" flag:"port"`
+	}
+
+	var asked []string
+	sources := []Source{
+		{
+			Tag: "flag",
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				if field == "host" {
+					return Value("flag"), nil
+				}
+				return nil, nil
+			},
+		},
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				if field == "HOST" {
+					return Value("env"), nil
+				}
+				return Value("8080"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "env", s.Host)
+
+	asked = nil
+	assert.NoError(t, From(sources).FirstWins().To(&s))
+	assert.Equal(t, "flag", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, []string{"host", "port", "PORT"}, asked)
+}
+
+func TestFillTimeWithLayout(t *testing.T) {
+
+	var s struct {
+		RFC3339 time.Time  `foo:"rfc3339"`
+		Date    time.Time  `foo:"date" layout:"2006-01-02"`
+		US      *time.Time `foo:"us,layout=01/02/2006"`
+	}
+
+	values := map[string]string{
+		"rfc3339": "2025-03-01T10:00:00Z",
+		"date":    "2025-03-01",
+		"us":      "03/01/2025",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), s.RFC3339)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), s.Date)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *s.US)
+
+	values["date"] = "01.03.2025"
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "date", parsedErr.Field)
+	assert.Equal(t, "01.03.2025", parsedErr.Value)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), s.Date)
+}
+
+func TestFillTimeFromUnixTimestamp(t *testing.T) {
+
+	var s struct {
+		Seconds      time.Time `foo:"seconds"`
+		Milliseconds time.Time `foo:"milliseconds" unit:"ms"`
+		Text         time.Time `foo:"text" unit:"s"`
+		Layout       time.Time `foo:"layout" layout:"20060102"`
+	}
+
+	values := map[string]string{
+		"seconds":      "1740823200",
+		"milliseconds": "1740823200123",
+		"text":         "2025-03-01T10:00:00Z",
+		"layout":       "20250301",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	expected := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.True(t, expected.Equal(s.Seconds))
+	assert.True(t, expected.Add(123*time.Millisecond).Equal(s.Milliseconds))
+	assert.True(t, expected.Equal(s.Text))
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), s.Layout)
+
+	var invalid struct {
+		Time time.Time `foo:"seconds" unit:"days"`
+	}
+	err := From(sources).To(&invalid)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `unknown time unit "days"`)
+}
+
+type cents int64
+
+func TestFillRegisteredType(t *testing.T) {
+
+	RegisterType(reflect.TypeOf(cents(0)), func(property reflect.Value, values []string) error {
+		euros, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return err
+		}
+		property.SetInt(int64(euros*100 + 0.5))
+		return nil
+	})
+	defer func() {
+		typeConvertersMu.Lock()
+		delete(typeConverters, reflect.TypeOf(cents(0)))
+		typeConvertersMu.Unlock()
+	}()
+
+	var s struct {
+		Price   cents   `foo:"price"`
+		Pointer *cents  `foo:"price"`
+		Slice   []cents `foo:"prices"`
+		Plain   int64   `foo:"plain"`
+	}
+
+	values := map[string][]string{
+		"price":  {"12.34"},
+		"prices": {"1", "0.5"},
+		"plain":  {"42"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, cents(1234), s.Price)
+	assert.Equal(t, cents(1234), *s.Pointer)
+	assert.Equal(t, []cents{100, 50}, s.Slice)
+	assert.Equal(t, int64(42), s.Plain)
+
+	values["price"] = []string{"free"}
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "free", parsedErr.Value)
+	assert.Equal(t, cents(1234), s.Price)
+}
+
+func TestFillWithEmptyAsZero(t *testing.T) {
+
+	type config struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Hosts   []string      `env:"HOSTS"`
+		Absent  int           `env:"ABSENT"`
+	}
+
+	values := map[string][]string{
+		"PORT":    {""},
+		"TIMEOUT": {""},
+		"HOSTS":   {""},
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	c := config{Port: 80, Timeout: time.Second, Hosts: []string{"a"}, Absent: 1}
+	assert.Error(t, From(sources).To(&c))
+
+	assert.NoError(t, From(sources).EmptyAsZero().To(&c))
+	assert.Equal(t, config{Absent: 1}, c)
+}
+
+func TestFillPointerToSliceAndMap(t *testing.T) {
+
+	var s struct {
+		Strings *[]string          `foo:"strings"`
+		Ints    *[]int             `foo:"ints"`
+		Labels  *map[string]string `foo:"labels"`
+		Ports   *map[string]int    `foo:"ports"`
+	}
+	existing := []int{1}
+	s.Ints = &existing
+
+	values := map[string][]string{
+		"strings": {"a", "b"},
+		"ints":    {"1", "2", "3"},
+		"labels":  {`{"app": "web"}`},
+		"ports":   {"http=80", "https=443"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"a", "b"}, *s.Strings)
+	assert.Equal(t, []int{1, 2, 3}, *s.Ints)
+	assert.Equal(t, []int{1}, existing)
+	assert.Equal(t, map[string]string{"app": "web"}, *s.Labels)
+	assert.Equal(t, map[string]int{"http": 80, "https": 443}, *s.Ports)
+
+	values["ints"] = []string{"4", "x"}
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "x", parsedErr.Value)
+	assert.Equal(t, []int{1, 2, 3}, *s.Ints)
+}
+
+func TestFillWithCanceledContext(t *testing.T) {
+
+	var s struct {
+		First  string `foo:"first"`
+		Second string `foo:"second"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var asked []string
+	sources := []Source{
+		{
+			Tag: "foo",
+			GetCtx: func(ctx context.Context, field string) (Valuer, error) {
+				asked = append(asked, field)
+				cancel()
+				return Value(field), nil
+			},
+		},
+	}
+
+	err := From(sources).ToContext(ctx, &s)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, []string{"first"}, asked)
+	assert.Equal(t, "first", s.First)
+	assert.Empty(t, s.Second)
+}
+
+func TestFillConcurrently(t *testing.T) {
+
+	var s struct {
+		Host    string `env:"HOST" flag:"host"`
+		Port    int    `env:"PORT"`
+		Debug   bool   `flag:"debug"`
+		Section struct {
+			Name string `env:"NAME"`
+		} `env:"SECTION_,prefix"`
+	}
+
+	var (
+		mu    sync.Mutex
+		asked []string
+	)
+	get := func(values map[string]string) func(ctx context.Context, field string) (Valuer, error) {
+		return func(ctx context.Context, field string) (Valuer, error) {
+			mu.Lock()
+			asked = append(asked, field)
+			mu.Unlock()
+
+			v, ok := values[field]
+			if !ok {
+				return nil, nil
+			}
+			return Value(v), nil
+		}
+	}
+
+	sources := []Source{
+		{Tag: "env", GetCtx: get(map[string]string{"HOST": "env", "PORT": "8080", "SECTION_NAME": "main"})},
+		{Tag: "flag", GetCtx: get(map[string]string{"host": "flag", "debug": "true"})},
+	}
+
+	assert.NoError(t, From(sources).Concurrency(4).To(&s))
+	assert.Equal(t, "flag", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.True(t, s.Debug)
+	assert.Equal(t, "main", s.Section.Name)
+	assert.ElementsMatch(t, []string{"HOST", "PORT", "SECTION_NAME", "host", "debug"}, asked)
+
+	sources[1].GetCtx = func(ctx context.Context, field string) (Valuer, error) {
+		return nil, errors.New("unavailable")
+	}
+	err := From(sources).Concurrency(4).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "flag", parsedErr.Source)
+	assert.EqualError(t, parsedErr.InnerError, "unavailable")
+}
+
+func BenchmarkFillConcurrently(b *testing.B) {
+
+	type config struct {
+		A string `slow:"a"`
+		B string `slow:"b"`
+		C string `slow:"c"`
+		D string `slow:"d"`
+		E string `slow:"e"`
+		F string `slow:"f"`
+		G string `slow:"g"`
+		H string `slow:"h"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "slow",
+			Get: func(field string) (Valuer, error) {
+				time.Sleep(time.Millisecond)
+				return Value(field), nil
+			},
+		},
+	}
+
+	for _, n := range []int{0, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var c config
+				if err := From(sources).Concurrency(n).To(&c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type benchmarkConfig struct {
+	Host    string        `env:"HOST,transform=trim" flag:"host"`
+	Port    int           `env:"PORT" flag:"port"`
+	Debug   bool          `env:"DEBUG" flag:"debug"`
+	Timeout time.Duration `env:"TIMEOUT" flag:"timeout"`
+	Tags    []string      `env:"TAGS" flag:"tags" delim:","`
+}
+
+func BenchmarkFillTagCache(b *testing.B) {
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return nil, nil
+			},
+		},
+		{
+			Tag: "flag",
+			Get: func(field string) (Valuer, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var c benchmarkConfig
+			if err := From(sources).To(&c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// unnamed struct types are not cached
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var c struct {
+				Host    string        `env:"HOST,transform=trim" flag:"host"`
+				Port    int           `env:"PORT" flag:"port"`
+				Debug   bool          `env:"DEBUG" flag:"debug"`
+				Timeout time.Duration `env:"TIMEOUT" flag:"timeout"`
+				Tags    []string      `env:"TAGS" flag:"tags" delim:","`
+			}
+			if err := From(sources).To(&c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestStructTagsAreCached(t *testing.T) {
+
+	tags := structTags(reflect.TypeOf(benchmarkConfig{}))
+	assert.Same(t, tags[0], structTags(reflect.TypeOf(benchmarkConfig{}))[0])
+
+	name, opts, ok := tags[0].parse("env")
+	assert.True(t, ok)
+	assert.Equal(t, "HOST", name)
+	assert.Equal(t, "trim", opts.values["transform"])
+
+	var unnamed struct {
+		Host string `env:"HOST"`
+	}
+	assert.False(t, structTags(reflect.TypeOf(unnamed))[0] == structTags(reflect.TypeOf(unnamed))[0])
+}
+
+type jsonPort int
+
+func (p *jsonPort) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("port must be a quoted string: %w", err)
+	}
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*p = jsonPort(port)
+	return nil
+}
+
+func TestFillJSONUnmarshaler(t *testing.T) {
+
+	var s struct {
+		Port    jsonPort   `foo:"port"`
+		Pointer *jsonPort  `foo:"port"`
+		Ports   []jsonPort `foo:"ports"`
+	}
+
+	values := map[string][]string{
+		"port":  {`"8080"`},
+		"ports": {`"80"`, `"443"`},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, jsonPort(8080), s.Port)
+	assert.Equal(t, jsonPort(8080), *s.Pointer)
+	assert.Equal(t, []jsonPort{80, 443}, s.Ports)
+
+	values["port"] = []string{"8080"}
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Contains(t, parsedErr.InnerError.Error(), "port must be a quoted string")
+	assert.Equal(t, jsonPort(8080), s.Port)
+}
+
+func TestFillWithCommaOptions(t *testing.T) {
+
+	var s struct {
+		Host  string   `env:"DB_HOST,required,default=localhost"`
+		Port  int      `env:"DB_PORT,required"`
+		Names []string `env:"DB_NAMES,delim=;"`
+		Plain string   `env:"PLAIN"`
+	}
+
+	var asked []string
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				switch field {
+				case "DB_PORT":
+					return Value("5432"), nil
+				case "DB_NAMES":
+					return Value("a;b"), nil
+				case "PLAIN":
+					return Value("plain"), nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"DB_HOST", "DB_PORT", "DB_NAMES", "PLAIN"}, asked)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, 5432, s.Port)
+	assert.Equal(t, []string{"a", "b"}, s.Names)
+	assert.Equal(t, "plain", s.Plain)
+}
+
+func TestFillWithSkippedSource(t *testing.T) {
+
+	var s struct {
+		Password string `env:"PASSWORD" secret:"-"`
+		Token    string `env:"TOKEN" secret:"token"`
+		Internal string `env:"-"`
+	}
+	s.Internal = "untouched"
+
+	var asked []string
+	get := func(field string) (Valuer, error) {
+		asked = append(asked, field)
+		return Value(field), nil
+	}
+
+	sources := []Source{
+		{Tag: "env", Get: get},
+		{Tag: "secret", Get: get},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"PASSWORD", "TOKEN", "token"}, asked)
+	assert.Equal(t, "PASSWORD", s.Password)
+	assert.Equal(t, "token", s.Token)
+	assert.Equal(t, "untouched", s.Internal)
+}
+
+func TestFillNestedStructWithSkippedSource(t *testing.T) {
+
+	type creds struct {
+		User     string `env:"USER"`
+		Password string `secret:"pw"`
+	}
+
+	var s struct {
+		Creds   creds  `secret:"-"`
+		Pointer *creds `secret:"-"`
+		Shared  creds
+	}
+
+	var asked []string
+	get := func(field string) (Valuer, error) {
+		asked = append(asked, field)
+		return Value("leaked"), nil
+	}
+
+	sources := []Source{{Tag: "secret", Get: get}}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"pw"}, asked)
+	assert.Empty(t, s.Creds.Password)
+	assert.Nil(t, s.Pointer)
+	assert.Equal(t, "leaked", s.Shared.Password)
+
+	// other sources still fill the excluded sections, also when the fields
+	// are fetched concurrently
+	sources = append(sources, Source{
+		Tag: "env",
+		Get: func(field string) (Valuer, error) {
+			return Value("env"), nil
+		},
+	})
+	for _, sources := range []Sources{From(sources), From(sources).Concurrency(2)} {
+		asked = nil
+		s.Creds, s.Pointer, s.Shared = creds{}, nil, creds{}
+		assert.NoError(t, sources.To(&s))
+		assert.Equal(t, []string{"pw"}, asked)
+		assert.Equal(t, creds{User: "env"}, s.Creds)
+		assert.Equal(t, &creds{User: "env"}, s.Pointer)
+		assert.Equal(t, creds{User: "env", Password: "leaked"}, s.Shared)
+	}
+}
+
+func TestMustTo(t *testing.T) {
+
+	var s struct {
+		Port int `env:"PORT"`
+	}
+
+	value := "8080"
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(value), nil
+			},
+		},
+	}
+
+	assert.NotPanics(t, func() { From(sources).MustTo(&s) })
+	assert.Equal(t, 8080, s.Port)
+
+	value = "http"
+	assert.Panics(t, func() { From(sources).MustTo(&s) })
+}
+
+func TestFillWithSourceTags(t *testing.T) {
+
+	var s struct {
+		Host     string `json:"host"`
+		Port     int    `yaml:"port"`
+		Both     string `json:"both_json" yaml:"both_yaml"`
+		Skipped  string `json:"-" yaml:"skipped"`
+		Other    string `env:"OTHER"`
+		Pinned   string `yaml:"pinned,source=json"`
+		Database struct {
+			Name string `yaml:"name"`
+		} `json:"db.,prefix"`
+	}
+
+	var asked []string
+	sources := []Source{
+		{
+			Tags: []string{"json", "yaml"},
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				if field == "port" {
+					return Value("8080"), nil
+				}
+				return Value(field), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"host", "port", "both_json", "skipped", "pinned", "db.name"}, asked)
+	assert.Equal(t, "host", s.Host)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "both_json", s.Both)
+	assert.Equal(t, "skipped", s.Skipped)
+	assert.Empty(t, s.Other)
+	assert.Equal(t, "pinned", s.Pinned)
+	assert.Equal(t, "db.name", s.Database.Name)
+}
+
+func TestFillStructUsingUnmarshal(t *testing.T) {
+
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+
+	var s struct {
+		Server  server  `foo:"server"`
+		Pointer *server `foo:"server"`
+	}
+
+	// decodes "key: value" lines
+	unmarshal := func(data []byte, v interface{}) error {
+		fields := map[string]interface{}{}
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, _ := strings.Cut(line, ": ")
+			if i, err := strconv.Atoi(value); err == nil {
+				fields[key] = i
+			} else {
+				fields[key] = value
+			}
+		}
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("host: localhost\nport: 8080"), nil
+			},
+		},
+	}
+
+	assert.Error(t, From(sources).To(&s))
+
+	assert.NoError(t, From(sources).UsingUnmarshal(unmarshal).To(&s))
+	assert.Equal(t, server{Host: "localhost", Port: 8080}, s.Server)
+	assert.Equal(t, server{Host: "localhost", Port: 8080}, *s.Pointer)
+}
+
+func TestFillCompositesUsingUnmarshal(t *testing.T) {
+
+	type server struct {
+		Address string `yaml:"host"`
+	}
+
+	var s struct {
+		Slice  []server          `foo:"slice"`
+		Map    map[string]server `foo:"map"`
+		Merged server            `foo:"merged,merge"`
+	}
+	s.Merged.Address = "localhost"
+
+	// renames the keys "host" to "Address"
+	var rename func(interface{}) interface{}
+	rename = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			m := map[string]interface{}{}
+			for key, value := range v {
+				if key == "host" {
+					key = "Address"
+				}
+				m[key] = rename(value)
+			}
+			return m
+		case []interface{}:
+			for i, value := range v {
+				v[i] = rename(value)
+			}
+		}
+		return v
+	}
+	unmarshal := func(data []byte, v interface{}) error {
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		b, err := json.Marshal(rename(generic))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}
+
+	values := map[string]string{
+		"slice":  `[{"host": "a"}, {"host": "b"}]`,
+		"map":    `{"first": {"host": "c"}}`,
+		"merged": `{"host": "d"}`,
+	}
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).UsingUnmarshal(unmarshal).To(&s))
+	assert.Equal(t, []server{{Address: "a"}, {Address: "b"}}, s.Slice)
+	assert.Equal(t, map[string]server{"first": {Address: "c"}}, s.Map)
+	assert.Equal(t, server{Address: "d"}, s.Merged)
+}
+
+func TestErrorOptions(t *testing.T) {
+
+	var s struct {
+		Date  time.Time `foo:"date,required" layout:"2006-01-02"`
+		Plain int       `foo:"plain"`
+	}
+
+	values := map[string]string{
+		"date":  "01.03.2025",
+		"plain": "1",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, map[string]string{"required": "", "layout": "2006-01-02"}, parsedErr.Options)
+
+	values["date"] = "2025-03-01"
+	values["plain"] = "invalid"
+	err = From(sources).To(&s)
+
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "plain", parsedErr.Field)
+	assert.Nil(t, parsedErr.Options)
+}
+
+func TestErrorPath(t *testing.T) {
+
+	type database struct {
+		Port int    `env:"PORT"`
+		Name string `env:"NAME" required:"true"`
+	}
+
+	var c struct {
+		Port     int `env:"APP_PORT"`
+		Services struct {
+			Database *database `env:"DB_,prefix"`
+		}
+	}
+
+	values := map[string]string{"APP_PORT": "80", "DB_PORT": "invalid"}
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&c)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "DB_PORT", parsedErr.Field)
+	assert.Equal(t, "Services.Database.Port", parsedErr.Path)
+
+	values["DB_PORT"] = "5432"
+	err = From(sources).To(&c)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "Services.Database.Name", parsedErr.Path)
+
+	values["APP_PORT"] = "invalid"
+	err = From(sources).To(&c)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "Port", parsedErr.Path)
+}
+
+func TestFillInterface(t *testing.T) {
+
+	var s struct {
+		Single  interface{} `foo:"single"`
+		Multi   any         `foo:"multi"`
+		Pointer *any        `foo:"single"`
+	}
+
+	values := map[string][]string{
+		"single": {"value"},
+		"multi":  {"a", "b"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "value", s.Single)
+	assert.Equal(t, []string{"a", "b"}, s.Multi)
+	assert.Equal(t, "value", *s.Pointer)
+}
+
+func TestFillNonEmptyInterface(t *testing.T) {
+
+	var s struct {
+		Stringer fmt.Stringer `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("value"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "unsupported property kind: interface type fmt.Stringer, only empty interfaces can be filled")
+	assert.Nil(t, s.Stringer)
+}
+
+func TestFillDefinedTypes(t *testing.T) {
+
+	type Level int8
+	type Port uint16
+	type Ratio float64
+	type Enabled bool
+	type Mode string
+	type Modes []Mode
+	type Labels map[Mode]Level
+
+	var s struct {
+		Level   Level         `foo:"level"`
+		Port    *Port         `foo:"port"`
+		Ratio   Ratio         `foo:"ratio"`
+		Enabled Enabled       `foo:"enabled"`
+		Mode    Mode          `foo:"mode"`
+		Modes   Modes         `foo:"modes"`
+		Levels  []Level       `foo:"modes_levels"`
+		Labels  Labels        `foo:"labels"`
+		Timeout time.Duration `foo:"timeout"`
+	}
+
+	values := map[string][]string{
+		"level":        {"3"},
+		"port":         {"8080"},
+		"ratio":        {"0.5"},
+		"enabled":      {"true"},
+		"mode":         {"debug"},
+		"modes":        {"debug", "release"},
+		"modes_levels": {"1", "2"},
+		"labels":       {"debug=1", "release=2"},
+		"timeout":      {"1s"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, Level(3), s.Level)
+	assert.Equal(t, Port(8080), *s.Port)
+	assert.Equal(t, Ratio(0.5), s.Ratio)
+	assert.Equal(t, Enabled(true), s.Enabled)
+	assert.Equal(t, Mode("debug"), s.Mode)
+	assert.Equal(t, Modes{"debug", "release"}, s.Modes)
+	assert.Equal(t, []Level{1, 2}, s.Levels)
+	assert.Equal(t, Labels{"debug": 1, "release": 2}, s.Labels)
+	assert.Equal(t, time.Second, s.Timeout)
+}
+
+func TestValidate(t *testing.T) {
+
+	type config struct {
+		Port    int           `env:"PORT"`
+		Host    string        `env:"HOST"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	values := map[string]string{
+		"PORT":    "8080",
+		"HOST":    "localhost",
+		"TIMEOUT": "1s",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	s := config{Port: 80, Host: "example.com"}
+	assert.NoError(t, From(sources).Validate(&s))
+	assert.Equal(t, config{Port: 80, Host: "example.com"}, s)
+
+	values["PORT"] = "http"
+	values["TIMEOUT"] = "soon"
+
+	err := From(sources).Validate(&s)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, config{Port: 80, Host: "example.com"}, s)
+
+	assert.Equal(t, InvalidTargetError{Type: reflect.TypeOf(s)}, From(sources).Validate(s))
+}
+
+func TestSourcePrefix(t *testing.T) {
+
+	var s struct {
+		Port     int `env:"PORT"`
+		Database struct {
+			Host string `env:"HOST"`
+		} `env:"DB_,prefix"`
+	}
+
+	values := map[string]string{
+		"APP_PORT":    "8080",
+		"APP_DB_HOST": "localhost",
+	}
+
+	var keys []string
+	sources := []Source{
+		{
+			Tag:    "env",
+			Prefix: "APP_",
+			Get: func(field string) (Valuer, error) {
+				keys = append(keys, field)
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"APP_PORT", "APP_DB_HOST"}, keys)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "localhost", s.Database.Host)
+
+	s.Port, s.Database.Host = 0, ""
+	sources[0].Get = nil
+	sources[0].GetAll = func(ctx context.Context, fields []string) (map[string]Valuer, error) {
+		assert.Equal(t, []string{"APP_PORT", "APP_DB_HOST"}, fields)
+		result := make(map[string]Valuer)
+		for _, field := range fields {
+			result[field] = Value(values[field])
+		}
+		return result, nil
+	}
+
+	assert.NoError(t, From([]Source{SnapshotSource(sources[0])}).To(&s))
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "localhost", s.Database.Host)
+}
+
+func TestSourceKeyTransform(t *testing.T) {
+
+	var s struct {
+		Port     int `env:"port"`
+		Database struct {
+			Host string `env:"host"`
+		} `env:"db_,prefix"`
+	}
+
+	values := map[string]string{
+		"APP_PORT":    "8080",
+		"APP_DB_HOST": "localhost",
+	}
+
+	var keys []string
+	sources := []Source{
+		{
+			Tag:          "env",
+			Prefix:       "app_",
+			KeyTransform: strings.ToUpper,
+			Get: func(field string) (Valuer, error) {
+				keys = append(keys, field)
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"APP_PORT", "APP_DB_HOST"}, keys)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "localhost", s.Database.Host)
+}
+
+func TestFillBigNumbers(t *testing.T) {
+
+	var s struct {
+		Int      big.Int    `foo:"int"`
+		IntPtr   *big.Int   `foo:"int"`
+		Float    big.Float  `foo:"float"`
+		FloatPtr *big.Float `foo:"float"`
+		Ints     []*big.Int `foo:"ints"`
+	}
+
+	values := map[string][]string{
+		"int":   {"123456789012345678901234567890"},
+		"float": {"1.5e400"},
+		"ints":  {"1", "99999999999999999999"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "123456789012345678901234567890", s.Int.String())
+	assert.Equal(t, "123456789012345678901234567890", s.IntPtr.String())
+	assert.Equal(t, "1.5e+400", s.Float.Text('g', 10))
+	assert.Equal(t, "1.5e+400", s.FloatPtr.Text('g', 10))
+	assert.Len(t, s.Ints, 2)
+	assert.Equal(t, "99999999999999999999", s.Ints[1].String())
+
+	values["int"] = []string{"0x10"}
+	err := From(sources).To(&s)
+	assert.EqualError(t, err, `failed to set field "int" from source "foo": invalid big integer "0x10"`)
+
+	values["int"] = []string{"1"}
+	values["float"] = []string{"many"}
+	err = From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "float", parsedErr.Field)
+	assert.Equal(t, "many", parsedErr.Value)
+}
+
+func TestFillURL(t *testing.T) {
+
+	var s struct {
+		Endpoint  url.URL    `foo:"endpoint"`
+		Pointer   *url.URL   `foo:"endpoint"`
+		Endpoints []*url.URL `foo:"endpoints"`
+	}
+
+	values := map[string][]string{
+		"endpoint":  {"https://example.com/path?q=1"},
+		"endpoints": {"http://a.example.com", "http://b.example.com"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "https", s.Endpoint.Scheme)
+	assert.Equal(t, "example.com", s.Endpoint.Host)
+	assert.Equal(t, "/path", s.Endpoint.Path)
+	assert.Equal(t, "1", s.Endpoint.Query().Get("q"))
+	assert.Equal(t, "https://example.com/path?q=1", s.Pointer.String())
+	assert.Len(t, s.Endpoints, 2)
+	assert.Equal(t, "b.example.com", s.Endpoints[1].Host)
+
+	values["endpoint"] = []string{"http://[::1"}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "endpoint", parsedErr.Field)
+	assert.Equal(t, "http://[::1", parsedErr.Value)
+	var urlErr *url.Error
+	assert.True(t, errors.As(parsedErr.InnerError, &urlErr))
+}
+
+func TestFillFromGetAll(t *testing.T) {
+
+	var c struct {
+		Host     string `doc:"host"`
+		Port     int    `doc:"port"`
+		Database struct {
+			Name string `doc:"name"`
+		} `doc:"db.,prefix"`
+	}
+
+	var calls int
+	document := []byte(`{"host": "localhost", "port": "8080", "db.name": "app"}`)
+	sources := []Source{
+		{
+			Tag: "doc",
+			Get: func(field string) (Valuer, error) {
+				t.Errorf("unexpected call of Get for %q", field)
+				return nil, nil
+			},
+			GetAll: func(_ context.Context, fields []string) (map[string]Valuer, error) {
+				calls++
+				var parsed map[string]string
+				if err := json.Unmarshal(document, &parsed); err != nil {
+					return nil, err
+				}
+				values := make(map[string]Valuer, len(fields))
+				for _, field := range fields {
+					if v, ok := parsed[field]; ok {
+						values[field] = Value(v)
+					}
+				}
+				return values, nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "localhost", c.Host)
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, "app", c.Database.Name)
+}
+
+func TestOnUnfilled(t *testing.T) {
+
+	var s struct {
+		Port     int    `env:"PORT"`
+		Host     string `env:"HSOT"`
+		Timeout  string `env:"TIMEOUT" default:"1s"`
+		Name     string `env:"NAME"`
+		Database struct {
+			User string `env:"USER"`
+			Pass string `env:"PASS"`
+		} `env:"DB_,prefix"`
+		ignored string
+	}
+	s.Name = "preset"
+
+	values := map[string]string{
+		"PORT":    "8080",
+		"DB_USER": "admin",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	var unfilled []string
+	err := From(sources).OnUnfilled(func(field string) {
+		unfilled = append(unfilled, field)
+	}).To(&s)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Host", "Database.Pass"}, unfilled)
+	assert.Equal(t, "", s.ignored)
+}
+
+func TestFillJSONNumber(t *testing.T) {
+
+	var s struct {
+		Amount  json.Number `foo:"amount"`
+		Payload struct {
+			Amount json.Number
+			Extra  map[string]interface{}
+		} `foo:"payload"`
+	}
+
+	values := map[string]string{
+		"amount":  "12345678901234567890.123456789",
+		"payload": `{"Amount": 12345678901234567890.123456789, "Extra": {"id": 9007199254740993}}`,
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, json.Number("12345678901234567890.123456789"), s.Amount)
+	assert.Equal(t, json.Number("12345678901234567890.123456789"), s.Payload.Amount)
+	assert.Equal(t, json.Number("9007199254740993"), s.Payload.Extra["id"])
+
+	// slices, maps and merges of structs keep the exact digits as well
+	type item struct {
+		ID interface{} `json:"id"`
+	}
+	var c struct {
+		Slice  []item          `foo:"slice"`
+		Map    map[string]item `foo:"map"`
+		Merged item            `foo:"merged,merge"`
+	}
+	values = map[string]string{
+		"slice":  `[{"id": 12345678901234567890}]`,
+		"map":    `{"a": {"id": 12345678901234567890}}`,
+		"merged": `{"id": 12345678901234567890}`,
+	}
+	assert.NoError(t, From(sources).To(&c))
+	assert.Equal(t, []item{{ID: json.Number("12345678901234567890")}}, c.Slice)
+	assert.Equal(t, map[string]item{"a": {ID: json.Number("12345678901234567890")}}, c.Map)
+	assert.Equal(t, item{ID: json.Number("12345678901234567890")}, c.Merged)
+
+	values["payload"] = `{"Amount": 1} {}`
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "payload", parsedErr.Field)
+}
+
+func TestFillEncodedBytes(t *testing.T) {
+
+	var s struct {
+		Base64 []byte  `foo:"base64" encoding:"base64"`
+		Hex    []byte  `foo:"hex,encoding=hex"`
+		Key    *[]byte `foo:"hex" encoding:"hex"`
+		Plain  []byte  `foo:"plain"`
+	}
+
+	values := map[string]string{
+		"base64": "aGVsbG8gd29ybGQ=",
+		"hex":    "deadbeef",
+		"plain":  "abc",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []byte("hello world"), s.Base64)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, s.Hex)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, *s.Key)
+	assert.Equal(t, []byte("abc"), s.Plain)
+
+	values["hex"] = "xyz"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "hex", parsedErr.Field)
+	assert.Equal(t, "xyz", parsedErr.Value)
+}
+
+func TestFillBytesUTF8(t *testing.T) {
+
+	type raw []byte
+
+	var s struct {
+		Bytes []byte `foo:"bar"`
+		Raw   raw    `foo:"bar"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("héllo"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []byte{'h', 0xc3, 0xa9, 'l', 'l', 'o'}, s.Bytes)
+	assert.Equal(t, raw("héllo"), s.Raw)
+}
+
+func TestFillTimeSlice(t *testing.T) {
+
+	var s struct {
+		RFC3339 []time.Time  `foo:"rfc3339"`
+		Dates   []time.Time  `foo:"dates" layout:"2006-01-02"`
+		Unix    []*time.Time `foo:"unix,unit=ms"`
+	}
+
+	values := map[string][]string{
+		"rfc3339": {"2020-01-02T03:04:05Z", "2021-06-07T08:09:10Z"},
+		"dates":   {"2020-01-02", "2021-06-07"},
+		"unix":    {"1577934245000"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []time.Time{
+		time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC),
+	}, s.RFC3339)
+	assert.Equal(t, []time.Time{
+		time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC),
+	}, s.Dates)
+	assert.Len(t, s.Unix, 1)
+	assert.True(t, s.Unix[0].Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	values["dates"] = []string{"2020-01-02", "2021-06-07T08:09:10Z"}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "dates", parsedErr.Field)
+	assert.Contains(t, parsedErr.InnerError.Error(), "element 1: ")
+}
+
+func TestFillIntegerOverflow(t *testing.T) {
+
+	var s struct {
+		Int8  int8  `foo:"int8"`
+		UInt8 uint8 `foo:"uint8"`
+		Int   int   `foo:"int"`
+		UInt  uint  `foo:"uint"`
+	}
+
+	values := map[string]string{
+		"int8":  "1",
+		"uint8": "1",
+		"int":   "1",
+		"uint":  "1",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	values["int8"] = "99999"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "99999", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `value 99999 overflows int8: strconv.ParseInt: parsing "99999": value out of range`)
+	assert.True(t, errors.Is(parsedErr.InnerError, strconv.ErrRange))
+
+	values["int8"], values["uint8"] = "1", "256"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "256", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `value 256 overflows uint8: strconv.ParseUint: parsing "256": value out of range`)
+
+	values["uint8"] = "-1"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `strconv.ParseUint: parsing "-1": invalid syntax`)
+
+	// int and uint are named by their bit size
+	values["uint8"], values["int"] = "1", "99999999999999999999"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, fmt.Sprintf(`value 99999999999999999999 overflows int%d: strconv.ParseInt: parsing "99999999999999999999": value out of range`, strconv.IntSize))
+
+	values["int"], values["uint"] = "1", "99999999999999999999"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, fmt.Sprintf(`value 99999999999999999999 overflows uint%d: strconv.ParseUint: parsing "99999999999999999999": value out of range`, strconv.IntSize))
+}
+
+func TestFillPointerNull(t *testing.T) {
+
+	port, name := 80, "preset"
+	var s struct {
+		Port  *int             `foo:"port"`
+		Name  *string          `foo:"name" null:"<nil>"`
+		Ports []*int           `foo:"ports"`
+		Inner *struct{ A int } `foo:"inner"`
+	}
+	s.Port, s.Name = &port, &name
+
+	values := map[string][]string{
+		"port":  {"null"},
+		"name":  {"<nil>"},
+		"ports": {"1", "null"},
+		"inner": {"null"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Nil(t, s.Port)
+	assert.Nil(t, s.Name)
+	assert.Len(t, s.Ports, 2)
+	assert.Equal(t, 1, *s.Ports[0])
+	assert.Nil(t, s.Ports[1])
+	assert.Nil(t, s.Inner)
+
+	values["port"] = []string{"8080"}
+	values["name"] = []string{"null"}
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 8080, *s.Port)
+	assert.Equal(t, "null", *s.Name)
+}
+
+func TestSourcesWithAndMerge(t *testing.T) {
+
+	var s struct {
+		Host string `env:"HOST" flag:"host"`
+		Port int    `env:"PORT" flag:"port"`
+	}
+
+	env := Source{
+		Tag: "env",
+		Get: func(field string) (Valuer, error) {
+			return Value(map[string]string{"HOST": "env", "PORT": "80"}[field]), nil
+		},
+	}
+	flag := Source{
+		Tag: "flag",
+		Get: func(field string) (Valuer, error) {
+			if field == "host" {
+				return Value("flag"), nil
+			}
+			return nil, nil
+		},
+	}
+
+	base := From(make([]Source, 0, 4))
+	withEnv := base.With(env)
+	withFlag := withEnv.With(flag)
+	merged := From(nil).Merge(withEnv).Merge(From([]Source{flag}))
+
+	assert.Len(t, base.sources, 0)
+	assert.Len(t, withEnv.sources, 1)
+	assert.Len(t, withFlag.sources, 2)
+	assert.Len(t, merged.sources, 2)
+
+	// appending to withEnv must not overwrite the flag source of withFlag
+	withEnv.With(env)
+	assert.Equal(t, "flag", withFlag.sources[1].Tag)
+
+	assert.NoError(t, withFlag.To(&s))
+	assert.Equal(t, "flag", s.Host)
+	assert.Equal(t, 80, s.Port)
+
+	s.Host, s.Port = "", 0
+	assert.NoError(t, merged.To(&s))
+	assert.Equal(t, "flag", s.Host)
+	assert.Equal(t, 80, s.Port)
+}
+
+func TestFillUnexported(t *testing.T) {
+
+	type database struct {
+		host string `env:"HOST"`
+	}
+
+	var s struct {
+		Port     int    `env:"PORT"`
+		secret   string `env:"SECRET"`
+		database `env:"DB_,prefix"`
+	}
+
+	values := map[string]string{
+		"PORT":    "8080",
+		"SECRET":  "s3cr3t",
+		"DB_HOST": "localhost",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 8080, s.Port)
+	assert.Empty(t, s.secret)
+	assert.Empty(t, s.host)
+
+	assert.NoError(t, From(sources).FillUnexported().To(&s))
+	assert.Equal(t, "s3cr3t", s.secret)
+	assert.Equal(t, "localhost", s.host)
+
+	s.secret, s.host = "", ""
+	assert.NoError(t, From(sources).FillUnexported().Concurrency(2).To(&s))
+	assert.Equal(t, "s3cr3t", s.secret)
+	assert.Equal(t, "localhost", s.host)
+}
+
+func TestSourceTransform(t *testing.T) {
+
+	var s struct {
+		Port int    `env:"PORT"`
+		Mode string `env:"MODE,transform=lower"`
+		Raw  string `env:"RAW"`
+	}
+
+	values := map[string]string{
+		"PORT": " 8080 ",
+		"MODE": " DEBUG\n",
+		"RAW":  " raw ",
+	}
+
+	var fields []string
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+			Transform: func(field, value string) string {
+				fields = append(fields, field)
+				if field == "RAW" {
+					return value
+				}
+				return strings.TrimSpace(value)
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"PORT", "MODE", "RAW"}, fields)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "debug", s.Mode)
+	assert.Equal(t, " raw ", s.Raw)
+	assert.Equal(t, " 8080 ", values["PORT"])
+}
+
+func TestRequireSources(t *testing.T) {
+
+	var s struct {
+		Port int `env:"PORT"`
+	}
+
+	assert.NoError(t, From(nil).To(&s))
+	assert.True(t, errors.Is(From(nil).RequireSources().To(&s), ErrNoSources))
+	assert.Equal(t, InvalidTargetError{Type: reflect.TypeOf(s)}, From(nil).RequireSources().To(s))
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value("8080"), nil
+			},
+		},
+	}
+	assert.NoError(t, From(sources).RequireSources().To(&s))
+	assert.Equal(t, 8080, s.Port)
+}
+
+// verbosity implements flag.Value.
+type verbosity int
+
+func (v *verbosity) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+func (v *verbosity) Set(value string) error {
+	switch value {
+	case "low":
+		*v = 1
+	case "high":
+		*v = 2
+	default:
+		return fmt.Errorf("unknown verbosity %q", value)
+	}
+	return nil
+}
+
+func TestFillFlagValue(t *testing.T) {
+
+	var s struct {
+		Level   verbosity   `foo:"level"`
+		Pointer *verbosity  `foo:"level"`
+		Levels  []verbosity `foo:"levels"`
+	}
+
+	values := map[string][]string{
+		"level":  {"high"},
+		"levels": {"low", "high"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, verbosity(2), s.Level)
+	assert.Equal(t, verbosity(2), *s.Pointer)
+	assert.Equal(t, []verbosity{1, 2}, s.Levels)
+
+	values["level"] = []string{"3"}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `unknown verbosity "3"`)
+}
+
+func TestFillIP(t *testing.T) {
+
+	var s struct {
+		IPv4     net.IP      `foo:"ipv4"`
+		IPv6     *net.IP     `foo:"ipv6"`
+		IPs      []net.IP    `foo:"ips"`
+		Network  net.IPNet   `foo:"network"`
+		Networks []net.IPNet `foo:"networks"`
+	}
+
+	values := map[string][]string{
+		"ipv4":     {"127.0.0.1"},
+		"ipv6":     {"2001:db8::1"},
+		"ips":      {"10.0.0.1", "::1"},
+		"network":  {"192.0.2.1/24"},
+		"networks": {"10.0.0.0/8", "2001:db8::/32"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.True(t, net.IPv4(127, 0, 0, 1).Equal(s.IPv4))
+	assert.Equal(t, "2001:db8::1", s.IPv6.String())
+	assert.Len(t, s.IPs, 2)
+	assert.Equal(t, "::1", s.IPs[1].String())
+	assert.Equal(t, "192.0.2.0/24", s.Network.String())
+	assert.Len(t, s.Networks, 2)
+	assert.Equal(t, "2001:db8::/32", s.Networks[1].String())
+
+	values["ipv4"] = []string{"300.0.0.1"}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "ipv4", parsedErr.Field)
+	assert.Equal(t, "300.0.0.1", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, "invalid IP address: 300.0.0.1")
+
+	values["ipv4"] = []string{"127.0.0.1"}
+	values["network"] = []string{"192.0.2.1"}
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "network", parsedErr.Field)
+	assert.EqualError(t, parsedErr.InnerError, "invalid CIDR address: 192.0.2.1")
+}
+
+func TestOptionalSource(t *testing.T) {
+
+	type config struct {
+		Host string `remote:"host" env:"HOST"`
+		Port int    `remote:"port" env:"PORT"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return Value(map[string]string{"HOST": "localhost", "PORT": "8080"}[field]), nil
+			},
+		},
+		{
+			Tag:      "remote",
+			Optional: true,
+			Get: func(field string) (Valuer, error) {
+				if field == "port" {
+					return Value("9090"), nil
+				}
+				return nil, errors.New("remote store unavailable")
+			},
+		},
+	}
+
+	var ignored []error
+	onIgnored := func(err error) {
+		ignored = append(ignored, err)
+	}
+
+	var c config
+	assert.NoError(t, From(sources).OnIgnoredError(onIgnored).To(&c))
+	assert.Equal(t, config{Host: "localhost", Port: 9090}, c)
+	assert.Len(t, ignored, 1)
+	assert.EqualError(t, ignored[0], `failed to set field "host" from source "remote": remote store unavailable`)
+
+	ignored, c = nil, config{}
+	assert.NoError(t, From(sources).OnIgnoredError(onIgnored).Concurrency(2).To(&c))
+	assert.Equal(t, config{Host: "localhost", Port: 9090}, c)
+	assert.Len(t, ignored, 1)
+
+	ignored, c = nil, config{}
+	sources[1].GetAll = func(context.Context, []string) (map[string]Valuer, error) {
+		return nil, errors.New("remote store unavailable")
+	}
+	assert.NoError(t, From(sources).OnIgnoredError(onIgnored).To(&c))
+	assert.Equal(t, config{Host: "localhost", Port: 8080}, c)
+	assert.Len(t, ignored, 1)
+	assert.EqualError(t, ignored[0], `failed to take snapshot of source "remote": remote store unavailable`)
+
+	sources[1].Optional = false
+	assert.EqualError(t, From(sources).To(&c), `failed to take snapshot of source "remote": remote store unavailable`)
+}
+
+func TestFirstNonEmpty(t *testing.T) {
+
+	var c struct {
+		Host string `env:"HOST" file:"host"`
+		Port int    `env:"PORT" file:"port" fallback:"port"`
+		Name string `env:"NAME" file:"name" fallback:"name"`
+	}
+
+	var asked []string
+	source := func(tag string, values map[string]string) Source {
+		return Source{
+			Tag: tag,
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, tag+":"+field)
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		}
+	}
+
+	sources := []Source{
+		source("env", map[string]string{"HOST": "", "PORT": "", "NAME": "env"}),
+		source("file", map[string]string{"host": "file", "port": ""}),
+		source("fallback", map[string]string{"port": "8080", "name": "fallback"}),
+	}
+
+	assert.NoError(t, From(sources).FirstNonEmpty().To(&c))
+	assert.Equal(t, "file", c.Host)
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, "env", c.Name)
+	assert.Equal(t, []string{
+		"env:HOST", "file:host",
+		"env:PORT", "file:port", "fallback:port",
+		"env:NAME",
+	}, asked)
+
+	// empty sentinels given before or after FirstNonEmpty are dropped as well
+	sources[0] = source("env", map[string]string{"HOST": "<nil>", "PORT": "", "NAME": "env"})
+	for _, s := range []Sources{
+		From(sources).FirstNonEmpty().EmptySentinels("<nil>"),
+		From(sources).EmptySentinels("<nil>").FirstNonEmpty(),
+	} {
+		c.Host, c.Port = "", 0
+		assert.NoError(t, s.To(&c))
+		assert.Equal(t, "file", c.Host)
+		assert.Equal(t, 8080, c.Port)
+	}
+
+	// without FirstNonEmpty the empty port is converted
+	assert.EqualError(t, From(sources).FirstWins().To(&c), `failed to set field "PORT" from source "env": strconv.ParseInt: parsing "": invalid syntax`)
+}
+
+func TestSet(t *testing.T) {
+
+	var i int
+	assert.NoError(t, Set(&i, "42"))
+	assert.Equal(t, 42, i)
+
+	var d time.Duration
+	assert.NoError(t, Set(&d, "1m30s"))
+	assert.Equal(t, 90*time.Second, d)
+
+	var server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	assert.NoError(t, Set(&server, `{"host": "localhost", "port": 8080}`))
+	assert.Equal(t, "localhost", server.Host)
+	assert.Equal(t, 8080, server.Port)
+
+	var hosts []string
+	assert.NoError(t, Set(&hosts, "a", "b"))
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	err := Set(&i, "many")
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "many", parsedErr.Value)
+	assert.EqualError(t, err, `failed to set value of type int: strconv.ParseInt: parsing "many": invalid syntax`)
+	assert.Equal(t, 42, i)
+
+	var small int8
+	assert.EqualError(t, Set(&small, "300"), `failed to set value of type int8: value 300 overflows int8: strconv.ParseInt: parsing "300": value out of range`)
+
+	assert.EqualError(t, Set(i, "1"), "given destination of type int is not a pointer")
+	assert.EqualError(t, Set((*int)(nil), "1"), "given destination is nil")
+	assert.EqualError(t, Set(nil, "1"), "given destination is nil")
+	assert.EqualError(t, Set(&i), "no values given")
+}
+
+func TestFillSliceLength(t *testing.T) {
+
+	var s struct {
+		Hosts []string `foo:"hosts" min:"1" max:"3"`
+		Ports *[]int   `foo:"ports,min=2"`
+		Tags  []string `foo:"tags" delim:"," max:"2"`
+	}
+
+	values := map[string][]string{
+		"hosts": {"a", "b"},
+		"ports": {"80", "443"},
+		"tags":  {"x,y"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"a", "b"}, s.Hosts)
+	assert.Equal(t, []int{80, 443}, *s.Ports)
+	assert.Equal(t, []string{"x", "y"}, s.Tags)
+
+	values["hosts"] = []string{"a", "b", "c", "d"}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "hosts", parsedErr.Field)
+	assert.EqualError(t, parsedErr.InnerError, "got 4 elements, expected at most 3")
+	assert.Equal(t, []string{"a", "b"}, s.Hosts)
+
+	values["hosts"] = []string{"a"}
+	values["ports"] = []string{"80"}
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "ports", parsedErr.Field)
+	assert.EqualError(t, parsedErr.InnerError, "got 1 elements, expected at least 2")
+
+	values["ports"] = []string{"80", "443"}
+	values["tags"] = []string{"x,y,z"}
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "got 3 elements, expected at most 2")
+}
+
+func TestFillNumberRange(t *testing.T) {
+
+	var s struct {
+		Port  int     `foo:"port" min:"1" max:"65535"`
+		Ratio float64 `foo:"ratio,min=0,max=1"`
+		Size  *uint   `foo:"size" max:"100"`
+	}
+
+	values := map[string]string{
+		"port":  "8080",
+		"ratio": "0.5",
+		"size":  "10",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, 0.5, s.Ratio)
+	assert.Equal(t, uint(10), *s.Size)
+
+	values["port"] = "0"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "port", parsedErr.Field)
+	assert.Equal(t, "0", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, "value 0 is below the minimum 1")
+	assert.Equal(t, 8080, s.Port)
+
+	values["port"] = "80"
+	values["ratio"] = "1.5"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "ratio", parsedErr.Field)
+	assert.EqualError(t, parsedErr.InnerError, "value 1.5 exceeds the maximum 1")
+
+	values["ratio"] = "1"
+	values["size"] = "101"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "value 101 exceeds the maximum 100")
+}
+
+func TestFillDurationRange(t *testing.T) {
+
+	var s struct {
+		Timeout time.Duration `foo:"timeout" min:"1s" max:"1m"`
+		Delay   time.Duration `foo:"delay,unit=ms,min=10,max=1s"`
+	}
+
+	values := map[string]string{
+		"timeout": "30s",
+		"delay":   "100",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 30*time.Second, s.Timeout)
+	assert.Equal(t, 100*time.Millisecond, s.Delay)
+
+	values["timeout"] = "500ms"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "timeout", parsedErr.Field)
+	assert.EqualError(t, parsedErr.InnerError, "value 500ms is below the minimum 1s")
+
+	values["timeout"] = "2m"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "value 2m0s exceeds the maximum 1m0s")
+
+	values["timeout"] = "1m"
+	values["delay"] = "5"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "value 5ms is below the minimum 10ms")
+
+	// bounds without a unit are not taken as nanoseconds
+	var d struct {
+		Timeout time.Duration `foo:"timeout" min:"1"`
+	}
+	err = From(sources).To(&d)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `invalid min option "1"`)
+}
+
+func TestFillOneOf(t *testing.T) {
+
+	var s struct {
+		Level string `foo:"level" oneof:"debug info warn error"`
+		Mode  string `foo:"mode,ignorecase" oneof:"dev prod"`
+	}
+
+	values := map[string]string{
+		"level": "info",
+		"mode":  "PROD",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "info", s.Level)
+	assert.Equal(t, "PROD", s.Mode)
+
+	values["level"] = "INFO"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "level", parsedErr.Field)
+	assert.Equal(t, "INFO", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `value "INFO" is not one of debug, info, warn, error`)
+
+	values["level"] = "warn"
+	values["mode"] = "staging"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `value "staging" is not one of dev, prod`)
+}
+
+func TestFillDefaultWithConstraints(t *testing.T) {
+
+	var s struct {
+		Port  int      `env:"PORT,default=0,min=1"`
+		Level string   `env:"LEVEL" default:"verbose" oneof:"debug info"`
+		Hosts []string `env:"HOSTS" default:"a,b,c" delim:"," max:"2"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	err := From(sources).ToAll(&s)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], `failed to set field "Port" from source "default": value 0 is below the minimum 1`)
+	assert.EqualError(t, errs[1], `failed to set field "Level" from source "default": value "verbose" is not one of debug, info`)
+	assert.EqualError(t, errs[2], `failed to set field "Hosts" from source "default": got 3 elements, expected at most 2`)
+	assert.Zero(t, s.Port)
+	assert.Empty(t, s.Level)
+	assert.Nil(t, s.Hosts)
+}
+
+func TestToWithProvenance(t *testing.T) {
+
+	var s struct {
+		Host     string `env:"HOST" flag:"host"`
+		Port     int    `env:"PORT" flag:"port"`
+		Timeout  string `env:"TIMEOUT" default:"1s"`
+		Missing  string `env:"MISSING"`
+		Database struct {
+			Name string `env:"NAME" flag:"name"`
+		} `env:"DB_,prefix" flag:"db.,prefix"`
+	}
+
+	env := map[string]string{"HOST": "env", "PORT": "80", "DB_NAME": "app"}
+	flags := map[string]string{"port": "8080", "db.name": "test"}
+
+	source := func(tag string, values map[string]string) Source {
+		return Source{
+			Tag: tag,
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		}
+	}
+
+	provenance, err := From([]Source{source("env", env), source("flag", flags)}).ToWithProvenance(&s)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Host":          "env",
+		"Port":          "flag",
+		"Timeout":       "default",
+		"Database.Name": "flag",
+	}, provenance)
+}
+
+func TestFillPointerChains(t *testing.T) {
+
+	var s struct {
+		Double **string    `foo:"name"`
+		Triple ***int      `foo:"port"`
+		Slice  **[]int     `foo:"ports"`
+		Time   **time.Time `foo:"time"`
+	}
+
+	values := map[string][]string{
+		"name":  {"handgover"},
+		"port":  {"8080"},
+		"ports": {"80", "443"},
+		"time":  {"2020-01-02T03:04:05Z"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, "handgover", **s.Double)
+	assert.Equal(t, 8080, ***s.Triple)
+	assert.Equal(t, []int{80, 443}, **s.Slice)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), **s.Time)
+
+	// existing pointers are replaced, the previous values stay untouched
+	previous := **s.Triple
+	values["port"] = []string{"9090"}
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 9090, ***s.Triple)
+	assert.Equal(t, 8080, *previous)
+}
+
+func TestUseFieldNameIfNoTag(t *testing.T) {
+
+	var s struct {
+		Port     int
+		Host     string        `env:"HOSTNAME"`
+		Secret   string        `env:"-"`
+		Timeout  time.Duration `default:"1s"`
+		Database struct {
+			Name string
+		} `env:"DB_,prefix"`
+		Server struct {
+			Debug bool
+		}
+	}
+
+	values := map[string]string{
+		"PORT":     "8080",
+		"HOSTNAME": "localhost",
+		"SECRET":   "s3cr3t",
+		"DB_NAME":  "app",
+		"DEBUG":    "true",
+	}
+
+	var asked []string
+	sources := []Source{
+		{
+			Tag:                 "env",
+			UseFieldNameIfNoTag: true,
+			NameTransform:       strings.ToUpper,
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"PORT", "HOSTNAME", "TIMEOUT", "DB_NAME", "DEBUG"}, asked)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "localhost", s.Host)
+	assert.Empty(t, s.Secret)
+	assert.Equal(t, time.Second, s.Timeout)
+	assert.Equal(t, "app", s.Database.Name)
+	assert.True(t, s.Server.Debug)
+
+	// without UseFieldNameIfNoTag untagged fields are not filled
+	s.Port = 0
+	sources[0].UseFieldNameIfNoTag = false
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 0, s.Port)
+}
+
+func TestUseFieldNameIfNoTagWithLeafStructs(t *testing.T) {
+
+	var s struct {
+		Endpoint url.URL
+		Proxy    *url.URL
+		Network  net.IPNet
+		Name     sql.NullString
+		Size     big.Int
+	}
+
+	values := map[string]string{
+		"ENDPOINT": "https://example.com/api",
+		"PROXY":    "http://proxy:3128",
+		"NETWORK":  "10.0.0.0/8",
+		"NAME":     "app",
+		"SIZE":     "12345678901234567890",
+	}
+
+	var asked []string
+	sources := []Source{
+		{
+			Tag:                 "env",
+			UseFieldNameIfNoTag: true,
+			NameTransform:       strings.ToUpper,
+			Get: func(field string) (Valuer, error) {
+				asked = append(asked, field)
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"ENDPOINT", "PROXY", "NETWORK", "NAME", "SIZE"}, asked)
+	assert.Equal(t, "https://example.com/api", s.Endpoint.String())
+	assert.Equal(t, "proxy:3128", s.Proxy.Host)
+	assert.Equal(t, "10.0.0.0/8", s.Network.String())
+	assert.Equal(t, sql.NullString{String: "app", Valid: true}, s.Name)
+	assert.Equal(t, "12345678901234567890", s.Size.String())
+}
+
+func TestFillDurationUnit(t *testing.T) {
+
+	var s struct {
+		Seconds  time.Duration  `foo:"seconds,unit=s"`
+		Millis   *time.Duration `foo:"millis" unit:"ms"`
+		Default  time.Duration  `foo:"default"`
+		Mixed    time.Duration  `foo:"mixed" unit:"s"`
+		Overflow time.Duration  `foo:"overflow" unit:"h"`
+	}
+
+	values := map[string]string{
+		"seconds":  "60",
+		"millis":   "1500",
+		"default":  "1h",
+		"mixed":    "1h30m",
+		"overflow": "1",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, time.Minute, s.Seconds)
+	assert.Equal(t, 1500*time.Millisecond, *s.Millis)
+	assert.Equal(t, time.Hour, s.Default)
+	assert.Equal(t, 90*time.Minute, s.Mixed)
+
+	values["default"] = "60"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "default", parsedErr.Field)
+
+	values["default"] = "1h"
+	values["overflow"] = "9999999"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, "duration 9999999h overflows time.Duration")
+}
+
+func TestResetBeforeFill(t *testing.T) {
+
+	var s struct {
+		Host     string        `env:"HOST"`
+		Port     int           `env:"PORT"`
+		Timeout  time.Duration `env:"TIMEOUT" default:"1s"`
+		Local    string
+		Database struct {
+			Name string `env:"NAME"`
+		} `env:"DB_,prefix"`
+	}
+
+	values := map[string]string{
+		"HOST":    "localhost",
+		"PORT":    "8080",
+		"TIMEOUT": "1m",
+		"DB_NAME": "app",
+	}
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	s.Local = "local"
+	assert.NoError(t, From(sources).ResetBeforeFill().To(&s))
+	assert.Equal(t, "localhost", s.Host)
+
+	// reloading keeps stale values by default
+	delete(values, "PORT")
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, 8080, s.Port)
+
+	delete(values, "TIMEOUT")
+	delete(values, "DB_NAME")
+	assert.NoError(t, From(sources).ResetBeforeFill().To(&s))
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, 0, s.Port)
+	assert.Equal(t, time.Second, s.Timeout)
+	assert.Empty(t, s.Database.Name)
+	assert.Equal(t, "local", s.Local)
+}
+
+func TestFillMergeFromSeveralSources(t *testing.T) {
+
+	type layered struct {
+		A int `json:"a"`
+		B int `json:"b"`
+		C struct {
+			D string `json:"d"`
+			E string `json:"e"`
+		} `json:"c"`
+	}
+
+	var s struct {
+		Merged   layered  `base:"config,merge" override:"config,merge"`
+		Pointer  *layered `base:"config,merge" override:"config,merge"`
+		Replaced layered  `base:"config" override:"config"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "base",
+			Get: func(field string) (Valuer, error) {
+				return Value(`{"a": 1, "c": {"d": "base", "e": "base"}}`), nil
+			},
+		},
+		{
+			Tag: "override",
+			Get: func(field string) (Valuer, error) {
+				return Value(`{"b": 2, "c": {"e": "override"}}`), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	for _, l := range []layered{s.Merged, *s.Pointer} {
+		assert.Equal(t, 1, l.A)
+		assert.Equal(t, 2, l.B)
+		assert.Equal(t, "base", l.C.D)
+		assert.Equal(t, "override", l.C.E)
+	}
+
+	// without merge the last source replaces the struct
+	assert.Equal(t, 0, s.Replaced.A)
+	assert.Equal(t, 2, s.Replaced.B)
+	assert.Empty(t, s.Replaced.C.D)
+}
+
+func TestFillSliceAppend(t *testing.T) {
+
+	var s struct {
+		Hosts    []string `env:"HOSTS,append" flag:"hosts,append"`
+		Ports    []int    `env:"PORTS" append:"true" delim:","`
+		Replaced []string `env:"HOSTS" flag:"hosts"`
+	}
+	s.Hosts = make([]string, 1, 10)
+	s.Hosts[0] = "a"
+	s.Ports = []int{80}
+	s.Replaced = []string{"a"}
+	original := s.Hosts
+
+	sources := []Source{
+		{
+			Tag: "env",
+			Get: func(field string) (Valuer, error) {
+				if field == "PORTS" {
+					return Value("443,8080"), nil
+				}
+				return Value("b", "c"), nil
+			},
+		},
+		{
+			Tag: "flag",
+			Get: func(field string) (Valuer, error) {
+				return Value("d"), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, s.Hosts)
+	assert.Equal(t, []int{80, 443, 8080}, s.Ports)
+	assert.Equal(t, []string{"d"}, s.Replaced)
+	assert.Equal(t, []string{"a"}, original)
+	assert.Empty(t, original[:2][1])
+}
+
+func TestErrorType(t *testing.T) {
+
+	var s struct {
+		Level   int8           `foo:"level"`
+		Timeout *time.Duration `foo:"timeout"`
+		Port    int            `foo:"port" default:"http"`
+	}
+
+	values := map[string]string{
+		"level":   "1000",
+		"timeout": "soon",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	err := From(sources).ToAll(&s)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 3)
+
+	var types []string
+	for _, err := range errs {
+		var parsedErr Error
+		assert.True(t, errors.As(err, &parsedErr))
+		types = append(types, parsedErr.Type)
+	}
+	assert.Equal(t, []string{"int8", "*time.Duration", "int"}, types)
+
+	var i int8
+	var parsedErr Error
+	assert.True(t, errors.As(Set(&i, "1000"), &parsedErr))
+	assert.Equal(t, "int8", parsedErr.Type)
+}
+
+func TestFillBoolLiterals(t *testing.T) {
+
+	var s struct {
+		Yes    bool  `foo:"yes"`
+		Off    bool  `foo:"off"`
+		Upper  *bool `foo:"upper"`
+		Custom bool  `foo:"custom" truthy:"enabled" falsy:"disabled"`
+	}
+	s.Off = true
+
+	values := map[string]string{
+		"yes":    "yes",
+		"off":    "off",
+		"upper":  "ON",
+		"custom": "Enabled",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.True(t, s.Yes)
+	assert.False(t, s.Off)
+	assert.True(t, *s.Upper)
+	assert.True(t, s.Custom)
+
+	values["yes"] = "maybe"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "yes", parsedErr.Field)
+	assert.Equal(t, "maybe", parsedErr.Value)
+	assert.EqualError(t, parsedErr.InnerError, `strconv.ParseBool: parsing "maybe": invalid syntax`)
+
+	values["yes"] = "1"
+	values["custom"] = "true"
+	err = From(sources).To(&s)
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "custom", parsedErr.Field)
+}
+
+// portRange implements encoding.TextUnmarshaler at the struct level.
+type portRange struct {
+	Min, Max int
+}
+
+func (r *portRange) UnmarshalText(text []byte) error {
+	min, max, ok := strings.Cut(string(text), "-")
+	if !ok {
+		return fmt.Errorf("invalid range %q", text)
+	}
+	var err error
+	if r.Min, err = strconv.Atoi(min); err != nil {
+		return err
+	}
+	r.Max, err = strconv.Atoi(max)
+	return err
+}
+
+func TestFillStructTextUnmarshaler(t *testing.T) {
+
+	var s struct {
+		Range   portRange   `foo:"range"`
+		Pointer *portRange  `foo:"range"`
+		Ranges  []portRange `foo:"ranges"`
+	}
+
+	values := map[string][]string{
+		"range":  {"1-5"},
+		"ranges": {"80-81", "8000-8080"},
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(values[field]...), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, portRange{Min: 1, Max: 5}, s.Range)
+	assert.Equal(t, portRange{Min: 1, Max: 5}, *s.Pointer)
+	assert.Equal(t, []portRange{{80, 81}, {8000, 8080}}, s.Ranges)
+
+	// JSON is not used for structs implementing encoding.TextUnmarshaler
+	values["range"] = []string{`{"Min": 1, "Max": 5}`}
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.EqualError(t, parsedErr.InnerError, `invalid range "{\"Min\": 1, \"Max\": 5}"`)
+}
+
+func TestErrUnsupportedKind(t *testing.T) {
+
+	var s struct {
+		Channel chan int `foo:"bar"`
+		Port    int      `foo:"port"`
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value("value"), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.True(t, errors.Is(err, ErrUnsupportedKind))
+	assert.EqualError(t, err, `failed to set field "bar" from source "foo": unsupported property kind "chan"`)
+
+	// parse errors are not unsupported kinds
+	s.Channel = nil
+	err = From([]Source{{
+		Tag: "foo",
+		Get: func(field string) (Valuer, error) {
+			if field == "bar" {
+				return nil, nil
+			}
+			return Value("value"), nil
+		},
+	}}).To(&s)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrUnsupportedKind))
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+
+	// interfaces with methods and prefixes of non-struct fields
+	var i struct {
+		Stringer fmt.Stringer `foo:"bar"`
+	}
+	err = From(sources).To(&i)
+	assert.True(t, errors.Is(err, ErrUnsupportedKind))
+
+	var p struct {
+		Port int `foo:"port,prefix"`
+	}
+	err = From(sources).To(&p)
+	assert.True(t, errors.Is(err, ErrUnsupportedKind))
+	assert.Contains(t, err.Error(), `unsupported property kind "int" for prefix option`)
+}
+
+func TestErrInvalidJSON(t *testing.T) {
+
+	var s struct {
+		Server struct {
+			Port int `json:"port"`
+		} `foo:"server"`
+	}
+
+	value := `{"port": 80`
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(value), nil
+			},
+		},
+	}
+
+	err := From(sources).To(&s)
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+	assert.EqualError(t, err, `failed to set field "server" from source "foo": invalid JSON: unexpected EOF`)
+
+	value = `{"port": "http"}`
+	err = From(sources).To(&s)
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, "port", typeErr.Field)
+
+	value = `{"port": 80}}`
+	err = From(sources).To(&s)
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	// other unmarshal functions are not wrapped
+	err = From(sources).UsingUnmarshal(func([]byte, interface{}) error {
+		return errors.New("invalid YAML")
+	}).To(&s)
+	assert.False(t, errors.Is(err, ErrInvalidJSON))
+}
+
+func TestFillCompositesWithInvalidJSON(t *testing.T) {
+
+	type server struct {
+		Port int `json:"port"`
+	}
+
+	var s struct {
+		Slice  []server          `foo:"slice"`
+		Map    map[string]server `foo:"map"`
+		Merged server            `foo:"merged,merge"`
+	}
+
+	values := map[string]string{}
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	for field, value := range map[string]string{
+		"slice":  `[{"port": "http"}]`,
+		"map":    `{"first": {"port": "http"}}`,
+		"merged": `{"port": "http"}`,
+	} {
+		values = map[string]string{field: value}
+		err := From(sources).To(&s)
+		assert.True(t, errors.Is(err, ErrInvalidJSON), field)
+		var typeErr *json.UnmarshalTypeError
+		assert.True(t, errors.As(err, &typeErr), field)
+	}
+
+	values = map[string]string{"slice": `[{"port": 80}`}
+	assert.True(t, errors.Is(From(sources).To(&s), ErrInvalidJSON))
+	values = map[string]string{"merged": `{"port": 80`}
+	assert.True(t, errors.Is(From(sources).To(&s), ErrInvalidJSON))
+}
+
+func TestFillSQLNull(t *testing.T) {
+
+	var s struct {
+		String  sql.NullString  `foo:"string"`
+		Int     sql.NullInt64   `foo:"int"`
+		Bool    *sql.NullBool   `foo:"bool"`
+		Float   sql.NullFloat64 `foo:"float"`
+		Time    sql.NullTime    `foo:"time" layout:"2006-01-02"`
+		Generic sql.Null[int16] `foo:"int"`
+		Missing sql.NullString  `foo:"missing"`
+	}
+
+	values := map[string]string{
+		"string": "hello",
+		"int":    "42",
+		"bool":   "yes",
+		"float":  "1.5",
+		"time":   "2020-01-02",
+	}
+
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				v, ok := values[field]
+				if !ok {
+					return nil, nil
+				}
+				return Value(v), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, sql.NullString{String: "hello", Valid: true}, s.String)
+	assert.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, s.Int)
+	assert.Equal(t, sql.NullBool{Bool: true, Valid: true}, *s.Bool)
+	assert.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, s.Float)
+	assert.Equal(t, sql.NullTime{Time: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}, s.Time)
+	assert.Equal(t, sql.Null[int16]{V: 42, Valid: true}, s.Generic)
+	assert.False(t, s.Missing.Valid)
+
+	values["int"] = "many"
+	err := From(sources).To(&s)
+	var parsedErr Error
+	assert.True(t, errors.As(err, &parsedErr))
+	assert.Equal(t, "int", parsedErr.Field)
+	assert.Equal(t, "many", parsedErr.Value)
+	assert.Equal(t, "sql.NullInt64", parsedErr.Type)
+	assert.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, s.Int)
+}
+
+func TestFillStructWithMergeKeepsFieldsWithoutJSON(t *testing.T) {
+
+	type inner struct {
+		C      int `json:"c"`
+		hidden string
+	}
+
+	type patched struct {
+		A      int    `json:"a"`
+		B      int    `json:"b"`
+		Secret string `json:"-"`
+		Inner  inner  `json:"inner"`
+		secret string
+	}
+
+	var s struct {
+		Value patched `foo:"bar,merge"`
+	}
+	s.Value = patched{A: 1, B: 2, Secret: "keep", Inner: inner{C: 3, hidden: "keep"}, secret: "keep"}
+
+	value := `{"a": 5}`
+	sources := []Source{
+		{
+			Tag: "foo",
+			Get: func(field string) (Valuer, error) {
+				return Value(value), nil
+			},
+		},
+	}
+
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, patched{A: 5, B: 2, Secret: "keep", Inner: inner{C: 3, hidden: "keep"}, secret: "keep"}, s.Value)
+
+	value = `{"b": null, "inner": {"c": null}}`
+	assert.NoError(t, From(sources).To(&s))
+	assert.Equal(t, patched{A: 5, Secret: "keep", Inner: inner{hidden: "keep"}, secret: "keep"}, s.Value)
+}
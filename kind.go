@@ -0,0 +1,61 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// KindParser parses a value for all fields of a kind.
+type KindParser func(string) (interface{}, error)
+
+var (
+	kindParsersMu sync.RWMutex
+	kindParsers   = map[reflect.Kind]KindParser{}
+)
+
+// RegisterKindParser overrides the parsing of every field of the given kind,
+// e.g. to accept "yes" and "no" for all bool fields. The parsed value has to
+// be assignable or convertible to the field type. Note that named types share
+// the kind of their underlying type, e.g. time.Duration is an reflect.Int64.
+//
+// Registration should happen at init time.
+func RegisterKindParser(kind reflect.Kind, parser KindParser) {
+	kindParsersMu.Lock()
+	defer kindParsersMu.Unlock()
+	kindParsers[kind] = parser
+}
+
+func kindParser(kind reflect.Kind) (KindParser, bool) {
+	kindParsersMu.RLock()
+	defer kindParsersMu.RUnlock()
+	parser, ok := kindParsers[kind]
+	return parser, ok
+}
+
+func setParsed(property reflect.Value, parser KindParser, values []string) error {
+	parsed, err := parser(values[0])
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(parsed)
+	switch {
+	case !v.IsValid():
+		property.Set(reflect.Zero(property.Type()))
+	case v.Type().AssignableTo(property.Type()):
+		property.Set(v)
+	case v.Kind() == property.Kind() && v.Type().ConvertibleTo(property.Type()):
+		property.Set(v.Convert(property.Type()))
+	default:
+		return fmt.Errorf("parsed value of type %s is not assignable to %s", v.Type(), property.Type())
+	}
+	return nil
+}
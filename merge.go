@@ -0,0 +1,114 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// mergeJSON applies value as JSON merge patch (RFC 7386) to the current value
+// of property, so fields which are not part of the patch are kept. The
+// function given by UsingUnmarshal decodes the patch and the merged value,
+// the current value is always encoded as JSON. JSON errors are wrapped with
+// ErrInvalidJSON.
+func mergeJSON(property reflect.Value, opts tagOptions, value string) error {
+	current, err := json.Marshal(property.Interface())
+	if err != nil {
+		return err
+	}
+
+	var target, patch interface{}
+	if err := unmarshalJSON(current, &target); err != nil {
+		return err
+	}
+	unmarshal := unmarshalJSON
+	if opts.unmarshal != nil {
+		unmarshal = opts.unmarshal
+	}
+	if err := unmarshal([]byte(value), &patch); err != nil {
+		return invalidJSON(opts, err)
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+
+	// structs are decoded into a copy of their current value, so fields
+	// without a JSON form are kept. Maps are decoded into a new value, as
+	// keys removed by the patch have to be deleted.
+	v := reflect.New(property.Type())
+	if property.Kind() == reflect.Struct {
+		v.Elem().Set(property)
+		zeroJSONFields(v.Elem())
+	}
+	if err := unmarshal(merged, v.Interface()); err != nil {
+		return invalidJSON(opts, err)
+	}
+	property.Set(v.Elem())
+	return nil
+}
+
+// zeroJSONFields sets the fields of the struct v which have a JSON form to
+// their zero value, so decoding the merged document into v sets them as if v
+// was new. Unexported fields and fields tagged `json:"-"` are kept, also of
+// nested structs.
+func zeroJSONFields(v reflect.Value) {
+	if v.Addr().Type().Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			zeroJSONFields(f)
+		case f.CanSet():
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+}
+
+// invalidJSON wraps err with ErrInvalidJSON unless it was returned by the
+// function given by UsingUnmarshal.
+func invalidJSON(opts tagOptions, err error) error {
+	if opts.unmarshal != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
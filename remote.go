@@ -0,0 +1,58 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RemoteConfigSource returns a source for the given tag which serves values
+// from a snapshot returned by fetch, e.g. from a central config service. The
+// snapshot is cached and fetched again once it is older than ttl. Errors of
+// fetch are returned by the source, keys missing in the snapshot leave the
+// field unset.
+func RemoteConfigSource(tag string, fetch func(ctx context.Context) (map[string]string, error), ttl time.Duration) Source {
+	r := &remoteConfig{fetch: fetch, ttl: ttl}
+	return Source{
+		Tag:    tag,
+		GetCtx: r.get,
+	}
+}
+
+type remoteConfig struct {
+	fetch func(ctx context.Context) (map[string]string, error)
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	snapshot  map[string]string
+	fetchedAt time.Time
+}
+
+func (r *remoteConfig) get(ctx context.Context, key string) (Valuer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.snapshot == nil || time.Since(r.fetchedAt) >= r.ttl {
+		snapshot, err := r.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot == nil {
+			snapshot = map[string]string{}
+		}
+		r.snapshot, r.fetchedAt = snapshot, time.Now()
+	}
+
+	value, ok := r.snapshot[key]
+	if !ok {
+		return nil, nil
+	}
+	return Value(value), nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Coercion describes the conversion of a source value into a field.
+type Coercion struct {
+	// Field is the name the source was asked for.
+	Field string
+	// Path is the dotted path of the struct field, e.g. "Database.Port".
+	Path string
+	// Source is the tag of the source.
+	Source string
+	// Value is the value returned by the source, after transforms.
+	Value string
+	// Result is the converted value of the field, formatted with fmt.
+	Result string
+}
+
+// ToWithReport fills the given struct like To and returns the coercions of
+// all fields that were set, e.g. for logging the parsed config on startup. A
+// field set by several sources is reported for each of them.
+func (sources Sources) ToWithReport(obj interface{}) ([]Coercion, error) {
+	var report []Coercion
+	sources.report = &report
+	err := sources.To(obj)
+	return report, err
+}
+
+// ToWithProvenance fills the given struct like To and returns the tag of the
+// source which supplied the final value of each field that was set, keyed by
+// the dotted path of the field. Fields set by their default option are
+// reported with the source "default".
+func (sources Sources) ToWithProvenance(obj interface{}) (map[string]string, error) {
+	report, err := sources.ToWithReport(obj)
+	provenance := make(map[string]string, len(report))
+	for _, coercion := range report {
+		provenance[coercion.Path] = coercion.Source
+	}
+	return provenance, err
+}
+
+// record adds the coercion of a field to the report, if one is requested.
+func (sources Sources) record(field, path, source string, values []string, converted reflect.Value) {
+	if sources.report == nil {
+		return
+	}
+
+	for converted.Kind() == reflect.Ptr && !converted.IsNil() {
+		converted = converted.Elem()
+	}
+
+	*sources.report = append(*sources.report, Coercion{
+		Field:  field,
+		Path:   path,
+		Source: source,
+		Value:  joinValues(values),
+		Result: fmt.Sprint(converted.Interface()),
+	})
+}
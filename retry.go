@@ -0,0 +1,59 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"context"
+	"time"
+)
+
+// RetrySource returns a copy of the source which calls its get functions up
+// to attempts times until no error is returned. Between attempts it waits
+// for backoff, which is doubled after each attempt. Waiting is aborted when
+// the context passed to GetCtx is done, the error of the last attempt is
+// returned otherwise.
+func RetrySource(source Source, attempts int, backoff time.Duration) Source {
+	get := source.get
+	getCtx := func(ctx context.Context, field string) (Valuer, error) {
+		return retry(ctx, attempts, backoff, func() (Valuer, error) {
+			return get(ctx, field)
+		})
+	}
+
+	source.GetCtx = getCtx
+	source.Get = func(field string) (Valuer, error) {
+		return getCtx(context.Background(), field)
+	}
+
+	if getAll := source.GetAll; getAll != nil {
+		source.GetAll = func(ctx context.Context, fields []string) (map[string]Valuer, error) {
+			return retry(ctx, attempts, backoff, func() (map[string]Valuer, error) {
+				return getAll(ctx, fields)
+			})
+		}
+	}
+	return source
+}
+
+func retry[T any](ctx context.Context, attempts int, backoff time.Duration, fn func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		v, err := fn()
+		if err == nil || attempt >= attempts {
+			return v, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
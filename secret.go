@@ -0,0 +1,32 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import "context"
+
+// SecretStore looks up secrets by name. The returned bool reports whether the
+// secret exists.
+type SecretStore interface {
+	GetSecret(ctx context.Context, name string) (string, bool, error)
+}
+
+// SecretSource returns a source for the tag "secret" which resolves fields,
+// e.g. `secret:"db/password"`, through the given store. Secrets which do not
+// exist leave the field unset.
+func SecretSource(store SecretStore) Source {
+	return Source{
+		Tag: "secret",
+		GetCtx: func(ctx context.Context, name string) (Valuer, error) {
+			secret, ok, err := store.GetSecret(ctx, name)
+			if err != nil || !ok {
+				return nil, err
+			}
+			return Value(secret), nil
+		},
+	}
+}
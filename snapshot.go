@@ -0,0 +1,123 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// SnapshotSource returns a copy of the source which serves all fields of a
+// call to To from one snapshot. Before filling, the fields of the struct are
+// enumerated and fetched at once with the GetAll function of the source, so
+// changes of the backend during filling are not seen. Sources with GetAll are
+// always served from a snapshot, sources without are asked for each field
+// before filling instead.
+func SnapshotSource(source Source) Source {
+	source.snapshot = true
+	return source
+}
+
+// takeSnapshots returns a copy of the sources in which every snapshot source
+// is replaced by a source serving the fields of t from a snapshot.
+func (sources Sources) takeSnapshots(ctx context.Context, t reflect.Type, tags []*fieldTag) (Sources, error) {
+	if !slices.ContainsFunc(sources.sources, Source.snapshotted) {
+		return sources, nil
+	}
+
+	snapshots := slices.Clone(sources.sources)
+	for i, source := range snapshots {
+		if !source.snapshotted() {
+			continue
+		}
+
+		fields := sources.fields(t, tags, sources.schema, source, nil, []reflect.Type{t})
+		values, err := source.getAll(ctx, fields)
+		if err != nil {
+			err = fmt.Errorf("failed to take snapshot of source %q: %w", source.id(), err)
+			if !source.Optional {
+				return sources, err
+			}
+			sources.ignoreError(err)
+		}
+		snapshots[i] = source.serve(values)
+	}
+
+	sources.sources = snapshots
+	return sources, nil
+}
+
+// snapshotted reports whether the source is served from a snapshot.
+func (source Source) snapshotted() bool {
+	return source.snapshot || source.GetAll != nil
+}
+
+func (source Source) getAll(ctx context.Context, fields []string) (map[string]Valuer, error) {
+	if source.GetAll != nil {
+		return source.GetAll(ctx, fields)
+	}
+
+	values := make(map[string]Valuer, len(fields))
+	for _, field := range fields {
+		v, err := source.get(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		values[field] = v
+	}
+	return values, nil
+}
+
+// serve returns a copy of the source which serves the given values.
+func (source Source) serve(values map[string]Valuer) Source {
+	source.Get = func(field string) (Valuer, error) {
+		return values[field], nil
+	}
+	source.GetCtx, source.GetAll, source.snapshot = nil, nil, false
+	return source
+}
+
+// fields enumerates the keys the source is asked for when filling a struct
+// of type t, including the keys of nested sections. The tags of nested
+// sections are taken from the nested structs of schema, if given.
+func (sources Sources) fields(t reflect.Type, tags []*fieldTag, schema reflect.Type, source Source, prefixes map[string]string, path []reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous && !sources.unexported || !sources.enabled(tags[i]) {
+			continue
+		}
+
+		if _, name, opts, ok := tags[i].parseSource(source); ok && !opts.has("prefix") {
+			fields = append(fields, source.key(prefixes, name))
+		}
+
+		sectionPrefixes, ok := sources.section(field.Type, tags[i], prefixes)
+		if !ok || len(path) > sources.depthLimit() || tags[i].excludes(source) {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || slices.Contains(path, ft) {
+			continue
+		}
+		schema := nestedSchema(schema, field.Name)
+		nested, err := schemaTags(ft, schema)
+		if err != nil {
+			// reported when the section is filled
+			continue
+		}
+		fields = append(fields, sources.fields(ft, nested, schema, source, sectionPrefixes, append(slices.Clip(path), ft))...)
+	}
+	return fields
+}
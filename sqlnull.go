@@ -0,0 +1,33 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isSQLNull reports whether t is one of the Null types of database/sql, e.g.
+// sql.NullString or sql.Null[T], which hold a value and a Valid flag.
+func isSQLNull(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.PkgPath() != "database/sql" || !strings.HasPrefix(t.Name(), "Null") {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	return ok && t.NumField() == 2 && valid.Index[0] == 1
+}
+
+// setSQLNull converts values into the value of a database/sql Null type and
+// marks it valid.
+func setSQLNull(property reflect.Value, opts tagOptions, values []string) error {
+	if err := setValue(property.Field(0), opts, values...); err != nil {
+		return err
+	}
+	property.Field(1).SetBool(true)
+	return nil
+}
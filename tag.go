@@ -0,0 +1,115 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagOptions holds the options of a field. Options are either given after the
+// name of the source tag (`foo:"bar,bytesize"`) or as a struct tag of their
+// own (`delim:","`), the former taking precedence.
+type tagOptions struct {
+	values map[string]string
+	tag    reflect.StructTag
+	// unmarshal decodes struct values, it is set by UsingUnmarshal.
+	unmarshal func([]byte, interface{}) error
+}
+
+// parseTag looks up the source tag key in tag and splits its value into the
+// name, which is passed to the source, and its comma separated options.
+// Options can carry a value (`key=value`). Like in encoding/json, a name of
+// "-" excludes the field from the source, it is not parsed. Use excludedTag to
+// tell an excluded field from a missing tag.
+func parseTag(tag reflect.StructTag, key string) (string, tagOptions, bool) {
+	value, ok := tag.Lookup(key)
+	if !ok || excludedTag(value) {
+		return "", tagOptions{}, false
+	}
+
+	opts := tagOptions{tag: tag}
+	name, rest, found := strings.Cut(value, ",")
+	if !found {
+		return name, opts, true
+	}
+
+	opts.values = map[string]string{}
+	for _, opt := range strings.Split(rest, ",") {
+		if opt == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(opt, "=")
+		opts.values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return name, opts, true
+}
+
+// excludedTag reports whether the value of a source tag excludes the field
+// from the source.
+func excludedTag(value string) bool {
+	return value == "-" || strings.HasPrefix(value, "-,")
+}
+
+func (o tagOptions) lookup(name string) (string, bool) {
+	if v, ok := o.values[name]; ok {
+		return v, true
+	}
+	return o.tag.Lookup(name)
+}
+
+// has reports whether the boolean option name is set. An option without a
+// value counts as set.
+func (o tagOptions) has(name string) bool {
+	return o.bool(name, false)
+}
+
+// bool returns the value of the boolean option name or def if it is not given.
+func (o tagOptions) bool(name string, def bool) bool {
+	v, ok := o.lookup(name)
+	if !ok {
+		return def
+	}
+	if v == "" {
+		return true
+	}
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
+// optionNames lists the options which can be given as struct tags of their
+// own.
+var optionNames = []string{
+	"append", "bytesize", "default", "delim", "duration", "encoding", "falsy",
+	"feature", "group", "ignorecase", "keepzero", "layout", "max", "merge",
+	"min", "null", "onerror", "oneof", "quoted", "required", "source",
+	"transform", "truthy", "unit",
+}
+
+// all returns the options after the name of the source tag and the options
+// given as struct tags of their own, the former taking precedence. It
+// returns nil if there are no options.
+func (o tagOptions) all() map[string]string {
+	var all map[string]string
+	for _, name := range optionNames {
+		if v, ok := o.tag.Lookup(name); ok {
+			if all == nil {
+				all = map[string]string{}
+			}
+			all[name] = v
+		}
+	}
+	for name, v := range o.values {
+		if all == nil {
+			all = map[string]string{}
+		}
+		all[name] = v
+	}
+	return all
+}
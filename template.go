@@ -0,0 +1,152 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TemplateFormat selects the output of Template.
+type TemplateFormat int
+
+const (
+	// TemplateEnv lists KEY=value lines, required keys are preceded by a comment.
+	TemplateEnv TemplateFormat = iota
+	// TemplateJSON renders a JSON object holding every key.
+	TemplateJSON
+	// TemplateKeys lists one key per line.
+	TemplateKeys
+)
+
+type templateEntry struct {
+	key        string
+	typ        reflect.Type
+	value      string
+	hasDefault bool
+	required   bool
+}
+
+// Template walks the fields of obj which carry the given tag and renders a
+// sample config for them, using the `default` and `required` options of the
+// fields. Nested sections are walked with their key prefix.
+func Template(obj interface{}, tag string, format TemplateFormat) string {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	entries := templateEntries(t, tag, "", []reflect.Type{t})
+
+	var b strings.Builder
+	switch format {
+	case TemplateJSON:
+		b.WriteString("{")
+		for i, e := range entries {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(e.key)
+			b.WriteString("\n  " + string(key) + ": " + e.jsonValue())
+		}
+		b.WriteString("\n}\n")
+	case TemplateKeys:
+		for _, e := range entries {
+			b.WriteString(e.key + "\n")
+		}
+	default:
+		for _, e := range entries {
+			if e.required {
+				b.WriteString("# required\n")
+			}
+			b.WriteString(e.key + "=" + e.value + "\n")
+		}
+	}
+	return b.String()
+}
+
+func templateEntries(t reflect.Type, tag, prefix string, path []reflect.Type) []templateEntry {
+	var entries []templateEntry
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, opts, ok := parseTag(field.Tag, tag)
+		if value, found := field.Tag.Lookup(tag); !ok && (!isNestedStruct(field.Type) || found && excludedTag(value)) {
+			continue
+		}
+
+		if !ok || opts.has("prefix") {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !slices.Contains(path, ft) && len(path) <= defaultMaxDepth {
+				entries = append(entries, templateEntries(ft, tag, prefix+name, append(slices.Clip(path), ft))...)
+			}
+			continue
+		}
+
+		e := templateEntry{
+			key:      prefix + name,
+			typ:      field.Type,
+			required: opts.has("required"),
+		}
+		e.value, e.hasDefault = opts.lookup("default")
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// jsonValue returns the default of the entry or a placeholder for its type.
+func (e templateEntry) jsonValue() string {
+	t := e.typ
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if e.hasDefault {
+		if t.Kind() != reflect.String && json.Valid([]byte(e.value)) {
+			return e.value
+		}
+		b, _ := json.Marshal(e.value)
+		return string(b)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return `"0s"`
+		}
+		return "0"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return `""`
+		}
+		return "[]"
+	case reflect.Map, reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return `""`
+		}
+		return "{}"
+	default:
+		return `""`
+	}
+}
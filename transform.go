@@ -0,0 +1,88 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Transform preprocesses a value before it is converted into the field type.
+type Transform func(string) (string, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]Transform{
+		"trim": func(s string) (string, error) {
+			return strings.TrimSpace(s), nil
+		},
+		"lower": func(s string) (string, error) {
+			return strings.ToLower(s), nil
+		},
+		"upper": func(s string) (string, error) {
+			return strings.ToUpper(s), nil
+		},
+		"base64decode": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+	}
+)
+
+// RegisterTransform registers a transform under the given name, replacing
+// any existing one. Fields use it with the tag option `transform=name`,
+// multiple transforms are applied in order: `transform=trim|lower`.
+//
+// Builtin transforms are trim, lower, upper and base64decode.
+func RegisterTransform(name string, transform Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = transform
+}
+
+// transformChain returns the transforms given by the transform option.
+func (o tagOptions) transformChain() ([]Transform, error) {
+	option, ok := o.lookup("transform")
+	if !ok || option == "" {
+		return nil, nil
+	}
+
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := strings.Split(option, "|")
+	chain := make([]Transform, len(names))
+	for i, name := range names {
+		transform, ok := transforms[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q", name)
+		}
+		chain[i] = transform
+	}
+	return chain, nil
+}
+
+func applyTransforms(chain []Transform, values []string) ([]string, error) {
+	if len(chain) == 0 {
+		return values, nil
+	}
+
+	transformed := make([]string, len(values))
+	for i, v := range values {
+		for _, transform := range chain {
+			var err error
+			if v, err = transform(v); err != nil {
+				return nil, err
+			}
+		}
+		transformed[i] = v
+	}
+	return transformed, nil
+}
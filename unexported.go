@@ -0,0 +1,29 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package handgover
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// FillUnexported returns a copy of the sources which fills unexported fields
+// as well, e.g. of internal config structs. Unexported fields are written
+// through package unsafe, bypassing the protection of reflect: invariants the
+// owner of the struct keeps for these fields are not checked, so only enable
+// it for structs of your own package.
+func (sources Sources) FillUnexported() Sources {
+	sources.unexported = true
+	return sources
+}
+
+// settable returns property, an unexported field of an addressable struct,
+// as a value which can be set.
+func settable(property reflect.Value) reflect.Value {
+	return reflect.NewAt(property.Type(), unsafe.Pointer(property.UnsafeAddr())).Elem()
+}
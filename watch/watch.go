@@ -0,0 +1,240 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package watch provides a handgover source which reads its values from a
+// JSON file and reloads them whenever the file changes. Calling To again
+// fills the struct with the current values.
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tpauling/handgover/v2"
+)
+
+// DefaultInterval is the interval WatchSource polls the file with.
+const DefaultInterval = time.Second
+
+// WatchSource watches the file at path with the DefaultInterval and returns a
+// source for the given tag serving its current values. The returned closer
+// stops watching the file.
+func WatchSource(path, tag string) (handgover.Source, io.Closer, error) {
+	w, err := New(path, DefaultInterval)
+	if err != nil {
+		return handgover.Source{}, nil, err
+	}
+	return w.Source(tag), w, nil
+}
+
+// Watcher polls a JSON config file and keeps its current values.
+//
+// The file has to contain a JSON object. Strings, numbers and booleans become
+// a single value, arrays become multiple values and objects are passed on as
+// raw JSON.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	mu     sync.RWMutex
+	values map[string][]string
+	err    error
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// New reads the file at path and starts polling it for changes every interval,
+// which has to be positive. A change is only loaded once the file stayed
+// untouched for another interval, so a file which is written in several steps
+// is not read half way.
+func New(path string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval %s is not positive", interval)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:     path,
+		interval: interval,
+		values:   values,
+		done:     make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.watch(stat)
+	return w, nil
+}
+
+// Source returns a source for the given tag which serves the current values of
+// the file. Keys which are not present in the file return no value.
+func (w *Watcher) Source(tag string) handgover.Source {
+	return handgover.Source{
+		Tag: tag,
+		Get: w.get,
+	}
+}
+
+// Err returns the error of the last reload, if any. The values of the last
+// successful load are kept in that case.
+func (w *Watcher) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
+
+// Close stops watching the file.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watcher) get(key string) (handgover.Valuer, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	values, ok := w.values[key]
+	if !ok {
+		return nil, nil
+	}
+	return handgover.Value(values...), nil
+}
+
+func (w *Watcher) watch(loaded os.FileInfo) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var pending os.FileInfo
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		stat, err := os.Stat(w.path)
+		if err != nil {
+			w.setErr(err)
+			continue
+		}
+
+		switch {
+		case sameFile(stat, loaded):
+			pending = nil
+		case pending == nil || !sameFile(stat, pending):
+			// the file changed, wait for it to settle before reading it
+			pending = stat
+		default:
+			values, err := load(w.path)
+			if err != nil {
+				w.setErr(err)
+				continue
+			}
+
+			w.mu.Lock()
+			w.values, w.err = values, nil
+			w.mu.Unlock()
+
+			loaded, pending = stat, nil
+		}
+	}
+}
+
+func (w *Watcher) setErr(err error) {
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+}
+
+func sameFile(a, b os.FileInfo) bool {
+	return a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+func load(path string) (map[string][]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+
+	values := make(map[string][]string, len(raw))
+	for key, msg := range raw {
+		v, err := toValues(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %q of %q: %w", key, path, err)
+		}
+		if v != nil {
+			values[key] = v
+		}
+	}
+	return values, nil
+}
+
+func toValues(msg json.RawMessage) ([]string, error) {
+	msg = bytes.TrimSpace(msg)
+	switch {
+	case bytes.Equal(msg, []byte("null")):
+		return nil, nil
+	case msg[0] == '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(msg, &elements); err != nil {
+			return nil, err
+		}
+
+		values := make([]string, 0, len(elements))
+		for _, element := range elements {
+			v, err := toValue(element)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	default:
+		v, err := toValue(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []string{v}, nil
+	}
+}
+
+func toValue(msg json.RawMessage) (string, error) {
+	msg = bytes.TrimSpace(msg)
+	if msg[0] != '"' {
+		return string(msg), nil
+	}
+
+	var s string
+	err := json.Unmarshal(msg, &s)
+	return s, err
+}
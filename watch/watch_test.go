@@ -0,0 +1,153 @@
+// Copyright (c) 2025 tpauling <github@pauling.io>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the “Software”), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpauling/handgover/v2"
+)
+
+type config struct {
+	Name  string   `cfg:"name"`
+	Port  int      `cfg:"port"`
+	Hosts []string `cfg:"hosts"`
+	Debug bool     `cfg:"debug"`
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherSource(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name": "hello", "port": 8080, "hosts": ["a", "b"], "debug": true}`)
+
+	w, err := New(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	var c config
+	assert.NoError(t, handgover.From([]handgover.Source{w.Source("cfg")}).To(&c))
+	assert.Equal(t, config{Name: "hello", Port: 8080, Hosts: []string{"a", "b"}, Debug: true}, c)
+}
+
+func TestWatchSource(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name": "hello", "port": 8080}`)
+
+	source, closer, err := WatchSource(path, "cfg")
+	assert.NoError(t, err)
+	defer closer.Close()
+
+	var c config
+	assert.NoError(t, handgover.From([]handgover.Source{source}).To(&c))
+	assert.Equal(t, config{Name: "hello", Port: 8080}, c)
+
+	_, closer, err = WatchSource(filepath.Join(t.TempDir(), "missing.json"), "cfg")
+	assert.Error(t, err)
+	assert.Nil(t, closer)
+}
+
+func TestWatcherSourceWithMissingKey(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"port": 8080, "name": null}`)
+
+	w, err := New(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	v, err := w.Source("cfg").Get("name")
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	v, err = w.Source("cfg").Get("missing")
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestWatcherReload(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"port": 8080}`)
+
+	w, err := New(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	sources := handgover.From([]handgover.Source{w.Source("cfg")})
+
+	var c config
+	assert.NoError(t, sources.To(&c))
+	assert.Equal(t, 8080, c.Port)
+
+	writeFile(t, path, `{"port": 9090, "name": "reloaded"}`)
+
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, sources.To(&c))
+		return c.Port == 9090
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "reloaded", c.Name)
+}
+
+func TestWatcherKeepsValuesOnInvalidFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"port": 8080}`)
+
+	w, err := New(path, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	writeFile(t, path, `{"port": invalid`)
+
+	assert.Eventually(t, func() bool {
+		return w.Err() != nil
+	}, time.Second, 10*time.Millisecond)
+
+	var c config
+	assert.NoError(t, handgover.From([]handgover.Source{w.Source("cfg")}).To(&c))
+	assert.Equal(t, 8080, c.Port)
+}
+
+func TestNewWithMissingFile(t *testing.T) {
+
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), time.Second)
+	assert.Error(t, err)
+}
+
+func TestNewWithInvalidFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `[1, 2]`)
+
+	_, err := New(path, time.Second)
+	assert.Error(t, err)
+}
+
+func TestNewWithInvalidInterval(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name": "handgover"}`)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		w, err := New(path, interval)
+		assert.EqualError(t, err, "watch interval "+interval.String()+" is not positive")
+		assert.Nil(t, w)
+	}
+}